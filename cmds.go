@@ -3,67 +3,217 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"html"
-	"html/template"
+	htmltemplate "html/template"
+	"io"
 	"os"
 	"os/exec"
 	"path"
-	"runtime"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/log"
-	yaml "gopkg.in/yaml.v3"
 )
 
-func (r *resume) execTmpl(templateDir, outDir, filename, order, tmplType string, check bool) error {
-	tmplFuncs := template.FuncMap{
-		"phone":   phone.String,
-		"date":    date.String,
-		"url":     social.String,
-		"getURL":  social.getURL,
-		"icon":    social.getIcon,
-		"listify": listify,
-		"have":    inFuture,
-		"trim":    strings.TrimSpace,
-		"today":   func() string { return time.Now().Format("2006-01-02") },
+// sectionFuncs are the template functions that depend on the resume and the
+// section being rendered. Any may be nil.
+type sectionFuncs struct {
+	title   func(name string) string // heading of a section by name
+	customs func() customSections    // custom sections of the current section
+	resume  *resume                  // resume being rendered, unescaped, for its theme and experience
+	now     time.Time                // time of the rendering, the current time when zero
+}
+
+// templateSet is a parsed set of templates, text/template for LaTeX and
+// html/template for HTML
+type templateSet interface {
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+// parseTemplates parses the templates of the pack and its parents, the
+// templates of a pack replacing the ones of the same name in its parents.
+// LaTeX is rendered with text/template from a resume escaped by
+// sanitizeResume, HTML with html/template which escapes the data itself.
+// The section functions give the templates the section being rendered.
+func parseTemplates(pack *templatePack, fn sectionFuncs, tmplType string) (templateSet, error) {
+	var th *theme
+	if fn.resume != nil {
+		th = &fn.resume.Theme
+	}
+	dates := newDateFuncs(th, tmplType)
+	// Every template of a rendering sees the same time
+	now := fn.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	tmplFuncs := map[string]interface{}{
+		"phone":    phone.String,
+		"tel":      phone.tel,
+		"icon":     social.getIcon,
+		"htmlIcon": social.getHTMLIcon,
+		"listify":  listify,
+		// have is kept for templates written before isOngoing and isExpected,
+		// which tested for a date in the future
+		"have":       func(d date) bool { return isOngoing(d) || isExpected(d) || d.time.After(now) },
+		"isOngoing":  isOngoing,
+		"isExpected": isExpected,
+		"trim":       strings.TrimSpace,
+		"tagged":     tagged,
+		"md":         markdownHTML,
+		"today":      func() string { return now.Format("2006-01-02") },
+		"title": func(name string) (string, error) {
+			if fn.title != nil {
+				name = fn.title(name)
+			}
+			if tmplType == "html" {
+				return name, nil
+			}
+			return sanitize(name)
+		},
+		// Profile links are resolved from the raw username, so they are
+		// escaped here rather than with the rest of the resume
+		"url": func(s social) (string, error) {
+			if tmplType == "html" {
+				return s.String(), nil
+			}
+			return sanitize(s.String())
+		},
+		"getURL": func(s social) (string, error) {
+			if tmplType == "html" {
+				return s.getURL(), nil
+			}
+			return sanitizeURL(s.getURL())
+		},
+		// Dates are formatted with the theme, or with dateFmt "January 2006"
+		"date":      dates.date,
+		"dateFmt":   dates.dateFmt,
+		"dateRange": dates.dateRange,
+		"duration":  func(start, end date) string { return duration(start, end, now) },
+		"totalYears": func(tags ...string) int {
+			if fn.resume == nil {
+				return 0
+			}
+			return fn.resume.totalYears(now, tags...)
+		},
+		"customs": func() customSections {
+			if fn.customs == nil {
+				return nil
+			}
+			return fn.customs()
+		},
 	}
+	// The html_ templates are parsed with html/template, the others with
+	// text/template, each pack replacing the templates of its parent
+	if tmplType == "html" {
+		tmpl := htmltemplate.New("All").Funcs(tmplFuncs)
+		for _, p := range pack.chain() {
+			files, err := p.templates(true)
+			if err != nil {
+				return nil, err
+			}
+			if len(files) == 0 {
+				continue
+			}
+			if _, err := tmpl.ParseFS(p.files, files...); err != nil {
+				return nil, fmt.Errorf("template pack %s: %w", p.Name, err)
+			}
+		}
+		return tmpl, nil
+	}
+	tmpl := template.New("All").Funcs(tmplFuncs)
+	for _, p := range pack.chain() {
+		files, err := p.templates(false)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		if _, err := tmpl.ParseFS(p.files, files...); err != nil {
+			return nil, fmt.Errorf("template pack %s: %w", p.Name, err)
+		}
+	}
+	return tmpl, nil
+}
 
-	tex, err := template.New("All").Funcs(tmplFuncs).ParseGlob(path.Join(templateDir, "*.tmpl"))
+func (r *resume) execTmpl(pack *templatePack, outDir, filename string, order []section, tmplType string, check bool) error {
+	// A section rendered by the template of another one shows its own title
+	var current section
+	title := func(name string) string {
+		if current.template() == name && current.Title != "" {
+			return current.Title
+		}
+		return sectionRegistry(order).title(name)
+	}
+	data := r
+	if tmplType != "html" {
+		escaped, err := r.latex()
+		if err != nil {
+			return fmt.Errorf("Error escaping resume: %w", err)
+		}
+		data = escaped
+	}
+	customs := func() customSections {
+		return current.customs(data.Custom, order)
+	}
+	tex, err := parseTemplates(pack, sectionFuncs{title: title, customs: customs, resume: r}, tmplType)
 	if err != nil {
 		log.Fatalf("Error parsing templates: %v", err)
 	}
-	log.Infof("Parsed templates from: %s", templateDir)
+	log.Infof("Parsed templates from: %s", pack)
 
 	var buffer bytes.Buffer
 
 	switch tmplType {
 	case "cover":
-		err = tex.ExecuteTemplate(&buffer, "cover", r)
+		buffer.WriteString(sectionMarker + "cover\n")
+		err = tex.ExecuteTemplate(&buffer, "cover", data)
 		if err != nil {
 			return fmt.Errorf("Error executing cover template: %w", err)
 		}
 		log.Infof("Successfully executed cover template")
-	case "resume":
-		err = tex.ExecuteTemplate(&buffer, "header", r)
+	case "resume", "html":
+		prefix := ""
+		// Section markers let LaTeX errors be traced back to their template
+		marker := func(name string) {
+			if tmplType != "html" {
+				buffer.WriteString(sectionMarker + name + "\n")
+			}
+		}
+		if tmplType == "html" {
+			prefix = "html_"
+		}
+		marker("header")
+		err = tex.ExecuteTemplate(&buffer, prefix+"header", data)
 		if err != nil {
 			return fmt.Errorf("Error executing header template: %w", err)
 		}
 		for _, section := range order {
-			err = tex.ExecuteTemplate(&buffer, mapping[section], r)
+			if !pack.supports(section.template()) {
+				log.Warnf("Template pack %s has no %s template, skipping the %s section", pack.Name, section.template(), section.Name)
+				continue
+			}
+			current = section
+			marker(section.Name)
+			err = tex.ExecuteTemplate(&buffer, prefix+section.template(), data)
 			if err != nil {
-				return fmt.Errorf("Error executing %s template: %w", mapping[section], err)
+				return fmt.Errorf("Error executing %s template: %w", prefix+section.template(), err)
 			}
 		}
-		err = tex.ExecuteTemplate(&buffer, "footer", r)
+		marker("footer")
+		err = tex.ExecuteTemplate(&buffer, prefix+"footer", data)
 		if err != nil {
 			return fmt.Errorf("Error executing footer template: %w", err)
 		}
 		log.Infof("Successfully executed all the templates")
 	}
 
-	filepath := path.Join(outDir, filename+".tex")
+	ext := ".tex"
+	if tmplType == "html" {
+		ext = ".html"
+	}
+	filepath := path.Join(outDir, filename+ext)
 	if check {
 		if _, err := os.Stat(filepath); err == nil {
 			var overwrite bool
@@ -83,42 +233,80 @@ func (r *resume) execTmpl(templateDir, outDir, filename, order, tmplType string,
 			}
 		}
 	}
-	decoded := html.UnescapeString(buffer.String())
 	texFile, err := os.Create(filepath)
 	if err != nil {
 		return fmt.Errorf("Error creating tex file: %w", err)
 	}
-	if _, err := texFile.WriteString(decoded); err != nil {
+	if _, err := buffer.WriteTo(texFile); err != nil {
 		return fmt.Errorf("Error writing tex file: %w", err)
 	}
 	if err := texFile.Close(); err != nil {
 		return fmt.Errorf("Error closing tex file: %w", err)
 	}
-	log.Infof("Successfully wrote tex file")
+	log.Infof("Successfully wrote %s file", strings.TrimPrefix(ext, "."))
 	return nil
 }
 
-func generatePDF(inDir, outDir, filename string) error {
+// generatePDF compiles the TeX file in a private build directory and moves
+// only the PDF into the PDF directory. The log is kept when the build fails.
+// Errors and box warnings from the log are reported against the resume fields.
+func (r *resume) generatePDF(c config, filename string) error {
 	var cmd *exec.Cmd
-	tex := path.Join(inDir, filename+".tex")
+	engine, err := getEngine(c.Engine)
+	if err != nil {
+		return err
+	}
+	tex := path.Join(c.TexDir, filename+".tex")
 	if _, err := os.Stat(tex); err != nil {
 		return fmt.Errorf("Error finding tex file: %w", err)
 	}
-	if _, err := exec.LookPath("pdflatex"); err != nil {
-		log.Fatalf("Please make sure pdflatex is installed and in your PATH: %v", err)
+	if _, err := exec.LookPath(engine.bin); err != nil {
+		log.Fatalf("Please make sure %s is installed and in your PATH: %v", engine.bin, err)
 	}
-	cmd = exec.Command("pdflatex", "-output-directory", outDir, tex)
 
+	buildDir, err := os.MkdirTemp("", "resume-build-")
+	if err != nil {
+		return fmt.Errorf("Error creating build directory: %w", err)
+	}
+	defer os.RemoveAll(buildDir)
+	log.Debugf("Building %s in %s", tex, buildDir)
+
+	cmd = exec.Command(engine.bin, engine.args(buildDir, tex)...)
 	out, err := cmd.CombinedOutput()
+	diags := r.texDiags(tex, path.Join(buildDir, filename+".log"), out)
 	if err != nil {
-		return fmt.Errorf("Error generating PDF: %w\nOutput: %s", err, out)
+		logFile := path.Join(c.LogDir, filename+".log")
+		if cpErr := moveFile(path.Join(buildDir, filename+".log"), logFile); cpErr != nil {
+			log.Warnf("Unable to keep the build log: %v", cpErr)
+			logFile = "unavailable"
+		}
+		var errs []string
+		for _, d := range diags {
+			if !d.warning {
+				errs = append(errs, "  "+d.String())
+			}
+		}
+		if len(errs) == 0 {
+			return fmt.Errorf("Error generating PDF: %w\nLog: %s\nOutput: %s", err, logFile, out)
+		}
+		return fmt.Errorf("Error generating PDF: %w\n%s\nLog: %s", err, strings.Join(errs, "\n"), logFile)
 	}
-	if runtime.GOOS == "linux" {
-		cleanup := exec.Command("find", outDir, "-type", "f", "!", "-name", "*.pdf", "-delete")
-		if _, err := cleanup.CombinedOutput(); err != nil {
-			return fmt.Errorf("Error cleaning up files: %w", err)
+	var warns []string
+	for _, d := range diags {
+		if d.warning {
+			warns = append(warns, "  "+d.String())
 		}
 	}
+	if len(warns) > maxTexWarnings {
+		warns = append(warns[:maxTexWarnings], fmt.Sprintf("  ... and %d more", len(warns)-maxTexWarnings))
+	}
+	if len(warns) > 0 {
+		log.Printf("LaTeX warnings in %s:\n%s", filename, strings.Join(warns, "\n"))
+	}
+
+	if err := moveFile(path.Join(buildDir, filename+".pdf"), path.Join(c.PdfDir, filename+".pdf")); err != nil {
+		return fmt.Errorf("Error moving PDF: %w", err)
+	}
 	log.Infof("Successfully generated PDF")
 
 	return nil
@@ -139,16 +327,52 @@ func scrapeLinkedin(dir, py string) error {
 	return nil
 }
 
-func (r *resume) parseResume(resumeFile string) error {
-	f, err := os.Open(resumeFile)
+// texDiags parses the build log, or the engine output when there is no log,
+// and locates the diagnostics in the TeX file
+func (r *resume) texDiags(tex, logFile string, out []byte) []texDiag {
+	texLog, err := os.ReadFile(logFile)
+	if err != nil {
+		texLog = out
+	}
+	diags := parseTexLog(string(texLog))
+	if len(diags) == 0 {
+		return nil
+	}
+	src, err := os.ReadFile(tex)
+	if err != nil {
+		log.Debugf("Unable to read %s: %v", tex, err)
+		return diags
+	}
+	escaped, err := r.latex()
 	if err != nil {
+		log.Debugf("Unable to escape the resume: %v", err)
+		return diags
+	}
+	escaped.locate(diags, string(src))
+	return diags
+}
+
+// loadResume merges the job specific resume into the base resume, if any,
+// decodes the result and keeps only the entries matching the selected tags.
+// See merge.go for the merge rules.
+func (r *resume) loadResume(c config, resumeFile string) error {
+	if err := r.decodeResume(c.BaseFile, resumeFile); err != nil {
 		return err
 	}
-	defer f.Close()
-	decoder := yaml.NewDecoder(f)
-	if err := decoder.Decode(&r); err != nil {
+	r.filterTags(c)
+	r.parsePhones(c.Region)
+	return r.linkSocials(c)
+}
+
+// decodeResume merges the job specific resume into the base resume, if any,
+// and decodes the result without filtering it
+func (r *resume) decodeResume(baseFile, resumeFile string) error {
+	doc, err := loadResumeDoc(baseFile, resumeFile)
+	if err != nil {
 		return err
 	}
-	log.Infof("Parsed resume file: %s", resumeFile)
+	if err := doc.decode(r); err != nil {
+		return fmt.Errorf("Error decoding resume: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// renderTest renders the resume with the built-in pack, as LaTeX for the
+// resume type and as a page for html, and returns the output
+func renderTest(t *testing.T, r *resume, order []section, tmplType string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := r.execTmpl(builtinPack(), dir, "test", order, tmplType, false); err != nil {
+		t.Fatal(err)
+	}
+	ext := ".tex"
+	if tmplType == "html" {
+		ext = ".html"
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "test"+ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestHeaderEmail(t *testing.T) {
+	r := resume{Info: info{Name: "Jane Doe", Email: "jane_doe+cv@example.com"}}
+	tex := renderTest(t, &r, nil, "resume")
+	want := `\href{mailto:jane_doe+cv@example.com }{ \faEnvelope \, \nolinkurl{jane_doe+cv@example.com} }`
+	if !strings.Contains(tex, want) {
+		t.Errorf("header lacks %q:\n%s", want, tex)
+	}
+	if r.Info.Email != "jane_doe+cv@example.com" {
+		t.Errorf("rendering changed the email to %q", r.Info.Email)
+	}
+}
+
+func TestHTMLOutput(t *testing.T) {
+	r := resume{
+		Info:        info{Name: "Jane <Doe>", Email: "jane@example.com"},
+		Education:   []school{{Name: "State University", Major: "R&D"}},
+		Experiences: []experience{{Company: "Acme", Title: "Engineer", Description: []bullet{{Text: "Cut costs by **40%**"}}}},
+	}
+	tests := []struct {
+		order string
+		first string
+	}{
+		{"ex", "State University"},
+		{"xe", "Acme"},
+	}
+	reg := sectionRegistry(defaultSections)
+	for _, tt := range tests {
+		order, err := reg.parseOrder(tt.order)
+		if err != nil {
+			t.Fatal(err)
+		}
+		html := renderTest(t, &r, order, "html")
+		for _, want := range []string{"<!DOCTYPE html>", "<style>", "Jane &lt;Doe&gt;", "R&amp;D", "<strong>40%</strong>", "</html>"} {
+			if !strings.Contains(html, want) {
+				t.Errorf("%s: the page lacks %q", tt.order, want)
+			}
+		}
+		if strings.Contains(html, `rel="stylesheet"`) || strings.Contains(html, sectionMarker) {
+			t.Errorf("%s: the page is not self-contained:\n%s", tt.order, html)
+		}
+		if i, j := strings.Index(html, "State University"), strings.Index(html, "Acme"); (i < j) != (tt.first == "State University") {
+			t.Errorf("%s: the sections are not in order", tt.order)
+		}
+	}
+}
+
+// fakeEngine puts a pdflatex in PATH that writes a PDF, an aux file and a
+// log into its output directory, and fails when the TeX file contains FAIL
+func fakeEngine(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake engine is a shell script")
+	}
+	bin := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 1 ]; do
+	if [ "$1" = "-output-directory" ]; then out=$2; fi
+	shift
+done
+name=$(basename "$1" .tex)
+touch "$out/$name.aux"
+if grep -q FAIL "$1"; then
+	printf '! Undefined control sequence.\nl.2 FAIL\n' > "$out/$name.log"
+	exit 1
+fi
+echo 'Output written' > "$out/$name.log"
+echo '%PDF' > "$out/$name.pdf"
+`
+	if err := os.WriteFile(filepath.Join(bin, "pdflatex"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGeneratePDF(t *testing.T) {
+	fakeEngine(t)
+	tests := []struct {
+		name    string
+		tex     string
+		wantErr string
+		pdf     bool
+		log     bool
+	}{
+		{"success", "\\documentclass{article}\nHello\n", "", true, false},
+		{"failure", "\\documentclass{article}\nFAIL\n", "Undefined control sequence", false, true},
+	}
+	for _, tt := range tests {
+		t.Setenv("TMPDIR", t.TempDir())
+		c := config{TexDir: t.TempDir(), PdfDir: t.TempDir(), LogDir: t.TempDir()}
+		if err := os.WriteFile(filepath.Join(c.TexDir, "cv.tex"), []byte(tt.tex), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Files already in the PDF directory are left alone
+		if err := os.WriteFile(filepath.Join(c.PdfDir, "notes.txt"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		var r resume
+		err := r.generatePDF(c, "cv")
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+		want := []string{"notes.txt"}
+		if tt.pdf {
+			want = []string{"cv.pdf", "notes.txt"}
+		}
+		if got := dirNames(t, c.PdfDir); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s: PDF directory holds %v, want %v", tt.name, got, want)
+		}
+		if _, err := os.Stat(filepath.Join(c.LogDir, "cv.log")); (err == nil) != tt.log {
+			t.Errorf("%s: log kept = %v, want %v", tt.name, err == nil, tt.log)
+		}
+		if got := dirNames(t, c.TexDir); len(got) != 1 {
+			t.Errorf("%s: TeX directory holds %v", tt.name, got)
+		}
+		if got := dirNames(t, os.Getenv("TMPDIR")); len(got) != 0 {
+			t.Errorf("%s: the build directory was left behind: %v", tt.name, got)
+		}
+	}
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
@@ -13,7 +13,7 @@ import (
 	yaml "gopkg.in/yaml.v3"
 )
 
-func (c *config) readConfig(file string) error {
+func (c *config) readConfig(file string, checkSchema bool) error {
 	if _, err := os.Stat(file); os.IsNotExist(err) {
 		log.Errorf("Error finding configuration file in current directory: %v", err)
 		var find bool
@@ -43,13 +43,27 @@ func (c *config) readConfig(file string) error {
 		}
 	}
 
+	if checkSchema {
+		errs, err := validateFile(file, configSchema)
+		if err != nil {
+			return fmt.Errorf("Error validating configuration file: %w", err)
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("Configuration file does not match the schema:\n%w", errs)
+		}
+	}
+	return readConfigFile(file, c)
+}
+
+// readConfigFile decodes the configuration file without prompting
+func readConfigFile(file string, c *config) error {
 	cfg, err := os.Open(file)
-	defer cfg.Close()
 	if err != nil {
 		return fmt.Errorf("Error opening configuration file: %w", err)
 	}
+	defer cfg.Close()
 	decoder := yaml.NewDecoder(cfg)
-	if err := decoder.Decode(&c); err != nil {
+	if err := decoder.Decode(c); err != nil {
 		return fmt.Errorf("Error decoding configuration file: %w", err)
 	}
 	return nil
@@ -94,6 +108,11 @@ func (cfg *config) buildForm() *huh.Form {
 	if err != nil {
 		log.Errorf("Error getting home directory: %v", err)
 	}
+	// {sections} in a description lists the sections of the registry
+	sections, err := cfg.registry()
+	if err != nil {
+		sections = defaultSections
+	}
 	for i := 0; i < v.NumField(); i++ {
 		field := t.Field(i)
 		tag := field.Tag.Get("form")
@@ -138,6 +157,7 @@ func (cfg *config) buildForm() *huh.Form {
 
 		case "input":
 			title, desc, _, pholder := parseTagOptions(tagParts[1:], field.Name)
+			desc = strings.ReplaceAll(desc, "{sections}", sections.describe(", "))
 			formBits = append(formBits,
 				huh.NewInput().
 					Title(title).
@@ -182,12 +202,25 @@ func parseTagOptions(options []string, fieldName string) (title, desc, ext, plac
 
 func (c *config) validate() error {
 	//TODO: Add more validation
-	f, err := os.Stat(c.TemplateDir)
-	if err != nil {
-		return fmt.Errorf("Error finding template directory: %w", err)
+	if c.TemplateDir != "" {
+		f, err := os.Stat(c.TemplateDir)
+		if err != nil {
+			return fmt.Errorf("Error finding template directory: %w", err)
+		}
+		if !f.IsDir() {
+			c.TemplateDir = filepath.Dir(c.TemplateDir)
+		}
+	}
+	if _, err := getEngine(c.Engine); err != nil {
+		return err
+	}
+	if c.Region != "" {
+		if _, ok := findRegion(c.Region); !ok {
+			return fmt.Errorf("Error in phone region: unknown region %q, expected one of: %s", c.Region, strings.Join(regionNames(), ", "))
+		}
 	}
-	if !f.IsDir() {
-		c.TemplateDir = filepath.Dir(c.TemplateDir)
+	if c.LogDir == "" {
+		c.LogDir = c.TexDir
 	}
 	if c.PdfFile == "" {
 		c.PdfFile = "default"
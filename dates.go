@@ -0,0 +1,331 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// datePrecision is how much of a date was written, so that a date is never
+// shown more precisely than it is known
+type datePrecision int
+
+const (
+	precisionDay datePrecision = iota
+	precisionMonth
+	precisionYear
+	precisionWeek
+	precisionSeason
+)
+
+// dateLayouts are the layouts read by parseDate besides ISO weeks and seasons
+var dateLayouts = []struct {
+	layout    string
+	precision datePrecision
+}{
+	{"2006-01-02", precisionDay},
+	{"01/02/2006", precisionDay},
+	{"2006-01", precisionMonth},
+	{"01/2006", precisionMonth},
+	{"Jan 2006", precisionMonth},
+	{"January 2006", precisionMonth},
+	{"2006", precisionYear},
+}
+
+var (
+	isoWeekRe = regexp.MustCompile(`^(\d{4})-?W(\d{2})$`)
+	seasonRe  = regexp.MustCompile(`(?i)^(?:(spring|summer|fall|autumn|winter)\s+(\d{4})|(\d{4})\s+(spring|summer|fall|autumn|winter))$`)
+)
+
+// seasons are the seasons read by parseDate and the month each one starts
+var seasons = map[string]time.Month{
+	"spring": time.March,
+	"summer": time.June,
+	"fall":   time.September,
+	"autumn": time.September,
+	"winter": time.December,
+}
+
+// dateLocale holds the names used to show dates in a language
+type dateLocale struct {
+	tag      language.Tag
+	present  string // end of an ongoing entry
+	expected string // before a date that has not happened yet
+	months   [12]string
+	short    [12]string
+	seasons  map[time.Month]string // by the month the season starts
+}
+
+var dateLocales = map[string]dateLocale{
+	"en": {language.English, "Present", "Expected",
+		[12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		[12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		map[time.Month]string{time.March: "Spring", time.June: "Summer", time.September: "Fall", time.December: "Winter"}},
+	"de": {language.German, "heute", "voraussichtlich",
+		[12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		[12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sep.", "Okt.", "Nov.", "Dez."},
+		map[time.Month]string{time.March: "Frühjahr", time.June: "Sommer", time.September: "Herbst", time.December: "Winter"}},
+	"fr": {language.French, "aujourd'hui", "prévu",
+		[12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		[12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		map[time.Month]string{time.March: "printemps", time.June: "été", time.September: "automne", time.December: "hiver"}},
+	"es": {language.Spanish, "actualidad", "previsto",
+		[12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		[12]string{"ene.", "feb.", "mar.", "abr.", "may.", "jun.", "jul.", "ago.", "sept.", "oct.", "nov.", "dic."},
+		map[time.Month]string{time.March: "primavera", time.June: "verano", time.September: "otoño", time.December: "invierno"}},
+	"it": {language.Italian, "oggi", "previsto",
+		[12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		[12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		map[time.Month]string{time.March: "primavera", time.June: "estate", time.September: "autunno", time.December: "inverno"}},
+	"pt": {language.Portuguese, "atual", "previsto",
+		[12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		[12]string{"jan.", "fev.", "mar.", "abr.", "mai.", "jun.", "jul.", "ago.", "set.", "out.", "nov.", "dez."},
+		map[time.Month]string{time.March: "primavera", time.June: "verão", time.September: "outono", time.December: "inverno"}},
+	"nl": {language.Dutch, "heden", "verwacht",
+		[12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		[12]string{"jan.", "feb.", "mrt.", "apr.", "mei", "jun.", "jul.", "aug.", "sep.", "okt.", "nov.", "dec."},
+		map[time.Month]string{time.March: "lente", time.June: "zomer", time.September: "herfst", time.December: "winter"}},
+	"pl": {language.Polish, "obecnie", "planowane",
+		[12]string{"styczeń", "luty", "marzec", "kwiecień", "maj", "czerwiec", "lipiec", "sierpień", "wrzesień", "październik", "listopad", "grudzień"},
+		[12]string{"sty", "lut", "mar", "kwi", "maj", "cze", "lip", "sie", "wrz", "paź", "lis", "gru"},
+		map[time.Month]string{time.March: "wiosna", time.June: "lato", time.September: "jesień", time.December: "zima"}},
+}
+
+// ongoingWords are the end dates of an ongoing entry, besides the present
+// of every locale
+var ongoingWords = []string{"present", "current", "now", "ongoing", "today"}
+
+// parseDate parses the supported date layouts, ISO weeks such as 2024-W05
+// and seasons such as Summer 2024. Present and its synonyms are ongoing, and
+// a date after "expected" has not happened yet. Anything else is kept as text.
+func parseDate(s string) date {
+	s = strings.TrimSpace(s)
+	if isOngoingWord(s) {
+		return date{text: s, ongoing: true}
+	}
+	for _, loc := range dateLocales {
+		if rest, ok := cutPrefixFold(s, loc.expected+" "); ok {
+			d := parseDate(rest)
+			if d.text == "" {
+				d.expected = true
+				return d
+			}
+		}
+	}
+	for _, l := range dateLayouts {
+		if t, err := time.Parse(l.layout, s); err == nil {
+			return date{time: t, precision: l.precision}
+		}
+	}
+	if m := isoWeekRe.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		if t, ok := isoWeekStart(year, week); ok {
+			return date{time: t, precision: precisionWeek}
+		}
+	}
+	if m := seasonRe.FindStringSubmatch(s); m != nil {
+		name, year := m[1], m[2]
+		if name == "" {
+			name, year = m[4], m[3]
+		}
+		y, _ := strconv.Atoi(year)
+		return date{time: time.Date(y, seasons[strings.ToLower(name)], 1, 0, 0, 0, 0, time.UTC), precision: precisionSeason}
+	}
+	return date{text: s}
+}
+
+func isOngoingWord(s string) bool {
+	for _, w := range ongoingWords {
+		if strings.EqualFold(s, w) {
+			return true
+		}
+	}
+	for _, loc := range dateLocales {
+		if strings.EqualFold(s, loc.present) {
+			return true
+		}
+	}
+	return false
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+// isoWeekStart returns the Monday of the ISO week
+func isoWeekStart(year, week int) (time.Time, bool) {
+	// January 4th is always in the first week
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, false
+	}
+	return monday, true
+}
+
+// format shows the date with the layout in the language of the locale. Dates
+// written with only a year, or as a season, ignore the layout; ISO weeks are
+// shown as the Monday they start on.
+func (t date) format(layout, locale string) string {
+	loc, ok := dateLocales[locale]
+	if !ok {
+		loc = dateLocales["en"]
+	}
+	switch {
+	case t.ongoing:
+		return capitalize(loc.present)
+	case t.text != "":
+		// Only the first letters are raised so that TBD stays TBD
+		return cases.Title(loc.tag, cases.NoLower).String(t.text)
+	case t.time.IsZero():
+		return ""
+	case t.precision == precisionYear:
+		return t.time.Format("2006")
+	case t.precision == precisionSeason:
+		return loc.seasons[t.time.Month()] + " " + t.time.Format("2006")
+	}
+	if layout == "" {
+		layout = defaultTheme.DateFormat
+	}
+	// The month tokens of the layout are written in the language of the
+	// locale and the rest of it is left to time.Format
+	m := t.time.Month() - 1
+	var out, chunk strings.Builder
+	for i := 0; i < len(layout); {
+		var name string
+		switch {
+		case strings.HasPrefix(layout[i:], "January"):
+			name, i = loc.months[m], i+len("January")
+		case strings.HasPrefix(layout[i:], "Jan"):
+			name, i = loc.short[m], i+len("Jan")
+		default:
+			chunk.WriteByte(layout[i])
+			i++
+			continue
+		}
+		out.WriteString(t.time.Format(chunk.String()))
+		out.WriteString(name)
+		chunk.Reset()
+	}
+	out.WriteString(t.time.Format(chunk.String()))
+	return out.String()
+}
+
+// capitalize raises the first letter, as the words of a locale are written
+// the way they appear in a sentence
+func capitalize(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// isOngoing reports whether the date is Present or one of its synonyms
+func isOngoing(d date) bool {
+	return d.ongoing
+}
+
+// isExpected reports whether the date was written as expected, with the
+// expected mapping or prefix
+func isExpected(d date) bool {
+	return d.expected
+}
+
+// dateFuncs are the date template functions, which format dates with the
+// date format and locale of the theme
+type dateFuncs struct {
+	theme    *theme
+	tmplType string
+}
+
+func newDateFuncs(th *theme, tmplType string) dateFuncs {
+	if th == nil {
+		th = &defaultTheme
+	}
+	return dateFuncs{theme: th, tmplType: tmplType}
+}
+
+// dateFmt formats the date with the layout, or with the date format of the
+// theme when empty. Text dates are escaped for LaTeX as they are not
+// sanitized with the rest of the resume.
+func (f dateFuncs) dateFmt(layout string, d date) (string, error) {
+	if layout == "" {
+		layout = f.theme.DateFormat
+	}
+	s := d.format(layout, f.theme.Locale)
+	if f.tmplType == "html" {
+		return s, nil
+	}
+	return sanitize(s)
+}
+
+func (f dateFuncs) date(d date) (string, error) {
+	return f.dateFmt("", d)
+}
+
+// dateRange shows the start and end of an entry, Present when it is ongoing
+// and Expected before an end that has not happened yet. Either may be empty.
+func (f dateFuncs) dateRange(start, end date) (string, error) {
+	from, err := f.date(start)
+	if err != nil {
+		return "", err
+	}
+	to, err := f.date(end)
+	if err != nil {
+		return "", err
+	}
+	if to != "" && !end.ongoing && isExpected(end) {
+		loc, ok := dateLocales[f.theme.Locale]
+		if !ok {
+			loc = dateLocales["en"]
+		}
+		to = capitalize(loc.expected) + " " + to
+	}
+	dash := ` \textendash{} `
+	if f.tmplType == "html" {
+		dash = " \u2013 "
+	}
+	switch {
+	case from == "":
+		return to, nil
+	case to == "":
+		return from, nil
+	}
+	return from + dash + to, nil
+}
+
+func (t date) String() string {
+	return t.format(defaultTheme.DateFormat, defaultTheme.Locale)
+}
+
+func (t date) MarshalYAML() (interface{}, error) {
+	if t.expected {
+		t.expected = false
+		s, err := t.MarshalYAML()
+		return map[string]interface{}{"expected": s}, err
+	}
+	if t.text != "" {
+		return t.text, nil
+	}
+	switch t.precision {
+	case precisionMonth:
+		return t.time.Format("2006-01"), nil
+	case precisionYear:
+		return t.time.Format("2006"), nil
+	case precisionWeek:
+		y, w := t.time.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w), nil
+	case precisionSeason:
+		return dateLocales["en"].seasons[t.time.Month()] + " " + t.time.Format("2006"), nil
+	}
+	return t.time.Format("2006-01-02"), nil
+}
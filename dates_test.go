@@ -0,0 +1,175 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in        string
+		want      time.Time
+		precision datePrecision
+		text      string
+	}{
+		{"2024-05-17", time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC), precisionDay, ""},
+		{"05/17/2024", time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC), precisionDay, ""},
+		{"2024-05", time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), precisionMonth, ""},
+		{"05/2024", time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), precisionMonth, ""},
+		{"May 2024", time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), precisionMonth, ""},
+		{"September 2024", time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC), precisionMonth, ""},
+		{"2024", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), precisionYear, ""},
+		{"2024-W05", time.Date(2024, time.January, 29, 0, 0, 0, 0, time.UTC), precisionWeek, ""},
+		{"2021W01", time.Date(2021, time.January, 4, 0, 0, 0, 0, time.UTC), precisionWeek, ""},
+		{"Summer 2024", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), precisionSeason, ""},
+		{"2024 autumn", time.Date(2024, time.September, 1, 0, 0, 0, 0, time.UTC), precisionSeason, ""},
+		{"2021-W53", time.Time{}, precisionDay, "2021-W53"},
+		{"TBD", time.Time{}, precisionDay, "TBD"},
+	}
+	for _, tt := range tests {
+		d := parseDate(tt.in)
+		if !d.time.Equal(tt.want) || d.precision != tt.precision || d.text != tt.text {
+			t.Errorf("parseDate(%q) = %v/%d/%q, want %v/%d/%q", tt.in, d.time, d.precision, d.text, tt.want, tt.precision, tt.text)
+		}
+	}
+}
+
+func TestParseDateOngoingAndExpected(t *testing.T) {
+	for _, s := range []string{"Present", "current", "NOW", "heute", "actualidad"} {
+		if d := parseDate(s); !d.ongoing {
+			t.Errorf("parseDate(%q) is not ongoing", s)
+		}
+	}
+	for _, s := range []string{"expected 2030-06", "Expected June 2030", "voraussichtlich 2030-06", "prévu 2030-06"} {
+		d := parseDate(s)
+		if !d.expected || d.precision != precisionMonth || d.time.Month() != time.June {
+			t.Errorf("parseDate(%q) = %+v, want an expected June 2030", s, d)
+		}
+	}
+	if d := parseDate("expected soon"); d.expected || d.text != "expected soon" {
+		t.Errorf("parseDate(%q) = %+v, want it kept as text", "expected soon", d)
+	}
+}
+
+func TestDateFormatLocales(t *testing.T) {
+	may := parseDate("2024-05-17")
+	sep := parseDate("2024-09")
+	tests := []struct {
+		locale, layout string
+		d              date
+		want           string
+	}{
+		{"en", "Jan 2006", may, "May 2024"},
+		{"en", "January 2006", sep, "September 2024"},
+		{"en", "Jan 2, 2006", may, "May 17, 2024"},
+		{"de", "Jan 2006", sep, "Sep. 2024"},
+		{"de", "January 2006", may, "Mai 2024"},
+		{"de", "2. January 2006", may, "17. Mai 2024"},
+		{"fr", "Jan 2006", sep, "sept. 2024"},
+		{"fr", "2 January 2006", may, "17 mai 2024"},
+		{"es", "Jan 2006", may, "may. 2024"},
+		{"es", "January 2006", may, "mayo 2024"},
+		{"it", "Jan 2006", may, "mag 2024"},
+		{"it", "January 2006", may, "maggio 2024"},
+		{"pt", "Jan 2006", may, "mai. 2024"},
+		{"pt", "January 2006", may, "maio 2024"},
+		{"nl", "Jan 2006", may, "mei 2024"},
+		{"nl", "January 2006", sep, "september 2024"},
+		{"pl", "Jan 2006", sep, "wrz 2024"},
+		{"pl", "January 2006", may, "maj 2024"},
+		{"es", "01/2006", may, "05/2024"},
+		{"xx", "Jan 2006", may, "May 2024"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.format(tt.layout, tt.locale); got != tt.want {
+			t.Errorf("format(%q, %q) of %s = %q, want %q", tt.layout, tt.locale, tt.d.time.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
+
+func TestDateFormatPrecision(t *testing.T) {
+	tests := []struct {
+		in, locale, want string
+	}{
+		{"2024", "de", "2024"},
+		{"Summer 2024", "en", "Summer 2024"},
+		{"Summer 2024", "fr", "été 2024"},
+		{"2024-W05", "en", "Jan 2024"},
+		{"Present", "en", "Present"},
+		{"Present", "es", "Actualidad"},
+		{"tbd soon", "en", "Tbd Soon"},
+	}
+	for _, tt := range tests {
+		if got := parseDate(tt.in).format("Jan 2006", tt.locale); got != tt.want {
+			t.Errorf("format of %q in %q = %q, want %q", tt.in, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestDateYAMLRoundTrip(t *testing.T) {
+	for _, s := range []string{"2024-05-17", "2024-05", "2024", "2024-W05", "Summer 2024", "Present", "TBD"} {
+		out, err := yaml.Marshal(parseDate(s))
+		if err != nil {
+			t.Fatalf("marshal %q: %v", s, err)
+		}
+		var d date
+		if err := yaml.Unmarshal(out, &d); err != nil {
+			t.Fatalf("unmarshal %q: %v", out, err)
+		}
+		if d != parseDate(s) {
+			t.Errorf("%q round-trips to %+v", s, d)
+		}
+	}
+
+	var d date
+	if err := yaml.Unmarshal([]byte("expected: 2030-06"), &d); err != nil {
+		t.Fatal(err)
+	}
+	if !d.expected || d.precision != precisionMonth {
+		t.Errorf("expected mapping read as %+v", d)
+	}
+	out, _ := yaml.Marshal(d)
+	if string(out) != "expected: 2030-06\n" {
+		t.Errorf("expected date written as %q", out)
+	}
+}
+
+func TestIsExpected(t *testing.T) {
+	future := date{time: time.Now().AddDate(1, 0, 0), precision: precisionDay}
+	if isExpected(future) {
+		t.Error("a future date counts as expected without being marked")
+	}
+	if !isExpected(parseDate("expected 2020-05")) {
+		t.Error("a date marked expected is not expected")
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	tests := []struct {
+		tmplType, locale string
+		start, end       string
+		want             string
+	}{
+		{"html", "en", "2022-09", "expected 2026-05", "Sep 2022 – Expected May 2026"},
+		{"html", "en", "2022-09", "Present", "Sep 2022 – Present"},
+		{"html", "en", "2022-09", "2099-01", "Sep 2022 – Jan 2099"},
+		{"html", "de", "2022-09", "expected 2026-05", "Sep. 2022 – Voraussichtlich Mai 2026"},
+		{"html", "en", "2022-09", "", "Sep 2022"},
+		{"html", "en", "", "2024-01", "Jan 2024"},
+		{"latex", "en", "2020", "2024-01", `2020 \textendash{} Jan 2024`},
+		{"latex", "en", "2020", "TBD & more", `2020 \textendash{} TBD \& More`},
+	}
+	for _, tt := range tests {
+		th := defaultTheme
+		th.Locale = tt.locale
+		got, err := newDateFuncs(&th, tt.tmplType).dateRange(parseDate(tt.start), parseDate(tt.end))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("dateRange(%q, %q) in %s/%s = %q, want %q", tt.start, tt.end, tt.tmplType, tt.locale, got, tt.want)
+		}
+	}
+}
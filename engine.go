@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const defaultEngine = "pdflatex"
+
+// texEngine is a program that compiles a TeX file into a PDF
+type texEngine struct {
+	bin     string
+	args    func(outDir, tex string) []string
+	unicode bool // reads UTF-8 and loads system fonts with fontspec
+}
+
+// latexArgs are shared by the engines that take the classic LaTeX command line
+func latexArgs(outDir, tex string) []string {
+	return []string{"-interaction=nonstopmode", "-halt-on-error", "-file-line-error", "-output-directory", outDir, tex}
+}
+
+// latexmkArgs runs as many passes as needed with the given latexmk engine flag
+func latexmkArgs(mode string) func(outDir, tex string) []string {
+	return func(outDir, tex string) []string {
+		return []string{mode, "-interaction=nonstopmode", "-halt-on-error", "-file-line-error", "-output-directory=" + outDir, tex}
+	}
+}
+
+var texEngines = map[string]texEngine{
+	"pdflatex":         {bin: "pdflatex", args: latexArgs},
+	"xelatex":          {bin: "xelatex", args: latexArgs, unicode: true},
+	"lualatex":         {bin: "lualatex", args: latexArgs, unicode: true},
+	"latexmk":          {bin: "latexmk", args: latexmkArgs("-pdf")},
+	"latexmk:pdflatex": {bin: "latexmk", args: latexmkArgs("-pdf")},
+	"latexmk:xelatex":  {bin: "latexmk", args: latexmkArgs("-pdfxe"), unicode: true},
+	"latexmk:lualatex": {bin: "latexmk", args: latexmkArgs("-pdflua"), unicode: true},
+	"tectonic": {bin: "tectonic", args: func(outDir, tex string) []string {
+		return []string{"--outdir", outDir, "--keep-logs", tex}
+	}, unicode: true},
+}
+
+// getEngine returns the named TeX engine, pdflatex when no name is given
+func getEngine(name string) (texEngine, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = defaultEngine
+	}
+	e, ok := texEngines[name]
+	if !ok {
+		return texEngine{}, fmt.Errorf("unknown TeX engine %q, expected one of: %s", name, strings.Join(engineNames(), ", "))
+	}
+	return e, nil
+}
+
+func engineNames() []string {
+	names := make([]string, 0, len(texEngines))
+	for name := range texEngines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		bin     string
+		args    []string
+		unicode bool
+	}{
+		{"", "pdflatex", []string{"-interaction=nonstopmode", "-halt-on-error", "-file-line-error", "-output-directory", "build", "cv.tex"}, false},
+		{" XeLaTeX ", "xelatex", []string{"-interaction=nonstopmode", "-halt-on-error", "-file-line-error", "-output-directory", "build", "cv.tex"}, true},
+		{"lualatex", "lualatex", []string{"-interaction=nonstopmode", "-halt-on-error", "-file-line-error", "-output-directory", "build", "cv.tex"}, true},
+		{"latexmk", "latexmk", []string{"-pdf", "-interaction=nonstopmode", "-halt-on-error", "-file-line-error", "-output-directory=build", "cv.tex"}, false},
+		{"latexmk:xelatex", "latexmk", []string{"-pdfxe", "-interaction=nonstopmode", "-halt-on-error", "-file-line-error", "-output-directory=build", "cv.tex"}, true},
+		{"latexmk:lualatex", "latexmk", []string{"-pdflua", "-interaction=nonstopmode", "-halt-on-error", "-file-line-error", "-output-directory=build", "cv.tex"}, true},
+		{"tectonic", "tectonic", []string{"--outdir", "build", "--keep-logs", "cv.tex"}, true},
+	}
+	for _, tt := range tests {
+		e, err := getEngine(tt.name)
+		if err != nil {
+			t.Errorf("%q: %v", tt.name, err)
+			continue
+		}
+		if e.bin != tt.bin || e.unicode != tt.unicode {
+			t.Errorf("%q: bin %s, unicode %v, want %s, %v", tt.name, e.bin, e.unicode, tt.bin, tt.unicode)
+		}
+		if got := e.args("build", "cv.tex"); !reflect.DeepEqual(got, tt.args) {
+			t.Errorf("%q: args = %q, want %q", tt.name, got, tt.args)
+		}
+	}
+	if _, err := getEngine("context"); err == nil || !strings.Contains(err.Error(), "expected one of: latexmk, ") {
+		t.Errorf("an unknown engine gave %v", err)
+	}
+}
+
+func TestPackEngine(t *testing.T) {
+	root := &templatePack{packInfo: packInfo{Name: "root", Engine: "xelatex"}}
+	tests := []struct {
+		pack *templatePack
+		want string
+	}{
+		{root, "xelatex"},
+		{&templatePack{packInfo: packInfo{Name: "child"}, parent: root}, "xelatex"},
+		{&templatePack{packInfo: packInfo{Name: "child", Engine: "tectonic"}, parent: root}, "tectonic"},
+		{&templatePack{packInfo: packInfo{Name: "alone"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.pack.engine(); got != tt.want {
+			t.Errorf("%s: engine = %q, want %q", tt.pack.Name, got, tt.want)
+		}
+	}
+}
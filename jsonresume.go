@@ -0,0 +1,636 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const jsonResumeSchema = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// jsonResume follows the JSON Resume standard (https://jsonresume.org/schema)
+// The keys without a field, at any depth, are kept in unknown so that they
+// can be reported instead of silently dropped.
+type jsonResume struct {
+	Schema       string            `json:"$schema,omitempty"`
+	Basics       jsonBasics        `json:"basics"`
+	Work         []jsonWork        `json:"work,omitempty"`
+	Education    []jsonEducation   `json:"education,omitempty"`
+	Projects     []jsonProject     `json:"projects,omitempty"`
+	Skills       []jsonSkill       `json:"skills,omitempty"`
+	Certificates []jsonCertificate `json:"certificates,omitempty"`
+	Publications []jsonPublication `json:"publications,omitempty"`
+	Awards       []jsonAward       `json:"awards,omitempty"`
+	Volunteer    []jsonVolunteer   `json:"volunteer,omitempty"`
+	Languages    []jsonLanguage    `json:"languages,omitempty"`
+	Interests    []jsonInterest    `json:"interests,omitempty"`
+	References   []jsonReference   `json:"references,omitempty"`
+	unknown      []string          // e.g. meta or work[0].company
+}
+
+type jsonBasics struct {
+	Name     string        `json:"name,omitempty"`
+	Label    string        `json:"label,omitempty"`
+	Image    string        `json:"image,omitempty"`
+	Email    string        `json:"email,omitempty"`
+	Phone    string        `json:"phone,omitempty"`
+	URL      string        `json:"url,omitempty"`
+	Summary  string        `json:"summary,omitempty"`
+	Location *jsonLocation `json:"location,omitempty"`
+	Profiles []jsonProfile `json:"profiles,omitempty"`
+}
+
+type jsonLocation struct {
+	Address     string `json:"address,omitempty"`
+	PostalCode  string `json:"postalCode,omitempty"`
+	City        string `json:"city,omitempty"`
+	CountryCode string `json:"countryCode,omitempty"`
+	Region      string `json:"region,omitempty"`
+}
+
+type jsonProfile struct {
+	Network  string `json:"network,omitempty"`
+	Username string `json:"username,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+type jsonWork struct {
+	Name        string   `json:"name,omitempty"`
+	Position    string   `json:"position,omitempty"`
+	Location    string   `json:"location,omitempty"`
+	Description string   `json:"description,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Highlights  []string `json:"highlights,omitempty"`
+}
+
+type jsonEducation struct {
+	Institution string   `json:"institution,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Area        string   `json:"area,omitempty"`
+	StudyType   string   `json:"studyType,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	Score       string   `json:"score,omitempty"`
+	Courses     []string `json:"courses,omitempty"`
+}
+
+type jsonProject struct {
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Highlights  []string `json:"highlights,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	StartDate   string   `json:"startDate,omitempty"`
+	EndDate     string   `json:"endDate,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Entity      string   `json:"entity,omitempty"`
+	Type        string   `json:"type,omitempty"`
+}
+
+type jsonSkill struct {
+	Name     string   `json:"name,omitempty"`
+	Level    string   `json:"level,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type jsonCertificate struct {
+	Name   string `json:"name,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Issuer string `json:"issuer,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+type jsonPublication struct {
+	Name        string `json:"name,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+}
+
+type jsonAward struct {
+	Title   string `json:"title,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Awarder string `json:"awarder,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+type jsonVolunteer struct {
+	Organization string   `json:"organization,omitempty"`
+	Position     string   `json:"position,omitempty"`
+	URL          string   `json:"url,omitempty"`
+	StartDate    string   `json:"startDate,omitempty"`
+	EndDate      string   `json:"endDate,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	Highlights   []string `json:"highlights,omitempty"`
+}
+
+type jsonLanguage struct {
+	Language string `json:"language,omitempty"`
+	Fluency  string `json:"fluency,omitempty"`
+}
+
+type jsonInterest struct {
+	Name     string   `json:"name,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type jsonReference struct {
+	Name      string `json:"name,omitempty"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// doiURL is the resolver prefix of a DOI link
+const doiURL = "https://doi.org/"
+
+func (j *jsonResume) UnmarshalJSON(data []byte) error {
+	type plain jsonResume
+	if err := json.Unmarshal(data, (*plain)(j)); err != nil {
+		return err
+	}
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	j.unknown = unknownKeys(raw, reflect.TypeOf(*j), "")
+	return nil
+}
+
+// unknownKeys lists the keys of the decoded JSON that t has no field for,
+// with their path, e.g. work[0].company
+func unknownKeys(v interface{}, t reflect.Type, at string) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var out []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			if name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ft, ok := fields[k]
+			if !ok {
+				out = append(out, joinPath(at, k))
+				continue
+			}
+			out = append(out, unknownKeys(v[k], ft, joinPath(at, k))...)
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice {
+			return nil
+		}
+		for i, e := range v {
+			out = append(out, unknownKeys(e, t.Elem(), fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	}
+	return out
+}
+
+// toResume maps the JSON Resume onto resume. The returned list names every
+// field that has no equivalent and was therefore not imported.
+func (j jsonResume) toResume() (resume, []string) {
+	var (
+		r       resume
+		skipped = append([]string{}, j.unknown...)
+	)
+
+	b := j.Basics
+	r.Info.Name = b.Name
+	r.Info.Email = b.Email
+	r.Info.Phone = phone{Number: b.Phone}
+	if b.Label != "" {
+		skipped = append(skipped, "basics.label")
+	}
+	if b.Image != "" {
+		skipped = append(skipped, "basics.image")
+	}
+	if b.URL != "" {
+		skipped = append(skipped, "basics.url")
+	}
+	if b.Summary != "" {
+		r.Summary = summary{Title: "Summary", Body: b.Summary}
+	}
+	if l := b.Location; l != nil {
+		r.Info.Address = address{Street: l.Address, City: l.City, State: l.Region, Zip: l.PostalCode}
+		if l.CountryCode != "" {
+			skipped = append(skipped, "basics.location.countryCode")
+		}
+	}
+	platforms, _ := config{}.platforms()
+	for _, p := range b.Profiles {
+		s := social{Platform: p.Network, Username: p.Username}
+		// The URL is only kept when the platform does not build the same one
+		if s.link(platforms); s.url != p.URL {
+			s.URL = p.URL
+		}
+		r.Info.Socials = append(r.Info.Socials, social{Platform: s.Platform, Username: s.Username, URL: s.URL})
+	}
+
+	for i, w := range j.Work {
+		r.Experiences = append(r.Experiences, experience{
+			Company:     w.Name,
+			Title:       w.Position,
+			Location:    w.Location,
+			StartDate:   parseDate(w.StartDate),
+			EndDate:     jsonEndDate(w.StartDate, w.EndDate),
+			Description: newBullets(w.Highlights),
+		})
+		if w.Summary != "" {
+			skipped = append(skipped, fmt.Sprintf("work[%d].summary", i))
+		}
+		if w.Description != "" {
+			skipped = append(skipped, fmt.Sprintf("work[%d].description", i))
+		}
+		if w.URL != "" {
+			skipped = append(skipped, fmt.Sprintf("work[%d].url", i))
+		}
+	}
+
+	for i, e := range j.Education {
+		r.Education = append(r.Education, school{
+			Name:       e.Institution,
+			Major:      e.Area,
+			StartDate:  parseDate(e.StartDate),
+			EndDate:    jsonEndDate(e.StartDate, e.EndDate),
+			GPA:        e.Score,
+			Coursework: e.Courses,
+		})
+		if e.URL != "" {
+			skipped = append(skipped, fmt.Sprintf("education[%d].url", i))
+		}
+		if e.StudyType != "" {
+			skipped = append(skipped, fmt.Sprintf("education[%d].studyType", i))
+		}
+	}
+
+	for i, p := range j.Projects {
+		var desc []string
+		if p.Description != "" {
+			desc = append(desc, p.Description)
+		}
+		r.Projects = append(r.Projects, project{
+			Name:         p.Name,
+			Description:  append(desc, p.Highlights...),
+			Technologies: p.Keywords,
+			Role:         strings.Join(p.Roles, ", "),
+			URL:          p.URL,
+			StartDate:    parseDate(p.StartDate),
+			EndDate:      jsonEndDate(p.StartDate, p.EndDate),
+		})
+		if p.Entity != "" {
+			skipped = append(skipped, fmt.Sprintf("projects[%d].entity", i))
+		}
+		if p.Type != "" {
+			skipped = append(skipped, fmt.Sprintf("projects[%d].type", i))
+		}
+	}
+
+	for i, s := range j.Skills {
+		r.Skills = append(r.Skills, skill{Name: s.Name, Keywords: s.Keywords})
+		if s.Level != "" {
+			skipped = append(skipped, fmt.Sprintf("skills[%d].level", i))
+		}
+	}
+
+	for _, c := range j.Certificates {
+		r.Certifications = append(r.Certifications, certification{
+			Name:       c.Name,
+			IssuingOrg: c.Issuer,
+			URL:        c.URL,
+			IssueDate:  parseDate(c.Date),
+		})
+	}
+
+	for _, p := range j.Publications {
+		pub := publication{
+			Title:       p.Name,
+			Venue:       p.Publisher,
+			Date:        parseDate(p.ReleaseDate),
+			URL:         p.URL,
+			Description: p.Summary,
+		}
+		if doi, ok := strings.CutPrefix(p.URL, doiURL); ok {
+			pub.DOI, pub.URL = doi, ""
+		}
+		r.Publications = append(r.Publications, pub)
+	}
+
+	for _, a := range j.Awards {
+		r.Awards = append(r.Awards, award{Title: a.Title, Awarder: a.Awarder, Date: parseDate(a.Date), Description: a.Summary})
+	}
+
+	for i, v := range j.Volunteer {
+		r.Volunteer = append(r.Volunteer, volunteer{
+			Organization: v.Organization,
+			Position:     v.Position,
+			URL:          v.URL,
+			StartDate:    parseDate(v.StartDate),
+			EndDate:      jsonEndDate(v.StartDate, v.EndDate),
+			Description:  newBullets(v.Highlights),
+		})
+		if v.Summary != "" {
+			skipped = append(skipped, fmt.Sprintf("volunteer[%d].summary", i))
+		}
+	}
+
+	for _, l := range j.Languages {
+		r.Languages = append(r.Languages, spokenLanguage{Name: l.Language, Level: l.Fluency})
+	}
+
+	for _, in := range j.Interests {
+		r.Interests = append(r.Interests, interest{Name: in.Name, Keywords: in.Keywords})
+	}
+
+	for _, ref := range j.References {
+		r.References = append(r.References, reference{Name: ref.Name, Text: ref.Reference})
+	}
+
+	return r, skipped
+}
+
+// toJSONResume maps resume onto the JSON Resume standard. The returned list
+// names every field that has no equivalent and was therefore not exported.
+func (r resume) toJSONResume() (jsonResume, []string) {
+	var (
+		j       jsonResume
+		skipped []string
+	)
+	j.Schema = jsonResumeSchema
+
+	j.Basics = jsonBasics{
+		Name:    r.Info.Name,
+		Email:   r.Info.Email,
+		Phone:   r.Info.Phone.Number,
+		Summary: r.Summary.Body,
+	}
+	if a := r.Info.Address; a != (address{}) {
+		j.Basics.Location = &jsonLocation{Address: a.Street, City: a.City, Region: a.State, PostalCode: a.Zip}
+	}
+	for _, s := range r.Info.Socials {
+		j.Basics.Profiles = append(j.Basics.Profiles, jsonProfile{Network: s.Platform, Username: s.Username, URL: s.getURL()})
+	}
+	if r.Info.Citizenship != "" {
+		skipped = append(skipped, "information.citizenship")
+	}
+	if r.Summary.Title != "" && r.Summary.Title != "Summary" {
+		skipped = append(skipped, "summary.title")
+	}
+	if !reflect.ValueOf(r.Theme).IsZero() {
+		skipped = append(skipped, "theme")
+	}
+
+	for _, e := range r.Experiences {
+		if len(e.Roles) == 0 {
+			e.Roles = []role{{Title: e.Title, StartDate: e.StartDate, EndDate: e.EndDate, Description: e.Description}}
+		}
+		// JSON Resume has no roles, every role is a work entry of its own
+		for _, role := range e.Roles {
+			location := role.Location
+			if location == "" {
+				location = e.Location
+			}
+			j.Work = append(j.Work, jsonWork{
+				Name:       e.Company,
+				Position:   role.Title,
+				Location:   location,
+				StartDate:  isoDate(role.StartDate),
+				EndDate:    isoDate(role.EndDate),
+				Highlights: bulletText(role.Description),
+			})
+		}
+	}
+
+	for i, s := range r.Education {
+		j.Education = append(j.Education, jsonEducation{
+			Institution: s.Name,
+			Area:        s.Major,
+			StartDate:   isoDate(s.StartDate),
+			EndDate:     isoDate(s.EndDate),
+			Score:       s.GPA,
+			Courses:     s.Coursework,
+		})
+		if s.Minor != "" {
+			skipped = append(skipped, fmt.Sprintf("education[%d].minor", i))
+		}
+		if s.Location != "" {
+			skipped = append(skipped, fmt.Sprintf("education[%d].location", i))
+		}
+		if len(s.Honors) > 0 {
+			skipped = append(skipped, fmt.Sprintf("education[%d].honors", i))
+		}
+		if s.Thesis != "" {
+			skipped = append(skipped, fmt.Sprintf("education[%d].thesis", i))
+		}
+	}
+
+	for i, p := range r.Projects {
+		jp := jsonProject{
+			Name:       p.Name,
+			Highlights: p.Description,
+			Keywords:   p.Technologies,
+			URL:        p.URL,
+			StartDate:  isoDate(p.StartDate),
+			EndDate:    isoDate(p.EndDate),
+		}
+		if p.Role != "" {
+			jp.Roles = []string{p.Role}
+		}
+		j.Projects = append(j.Projects, jp)
+		if p.Repository != "" {
+			skipped = append(skipped, fmt.Sprintf("projects[%d].repository", i))
+		}
+	}
+
+	for _, s := range r.Skills {
+		j.Skills = append(j.Skills, jsonSkill{Name: s.Name, Keywords: s.Keywords})
+	}
+
+	for i, c := range r.Certifications {
+		j.Certificates = append(j.Certificates, jsonCertificate{Name: c.Name, Issuer: c.IssuingOrg, URL: c.URL, Date: isoDate(c.IssueDate)})
+		if !c.ExpirationDate.IsZero() {
+			skipped = append(skipped, fmt.Sprintf("certifications[%d].expiration_date", i))
+		}
+		if c.CredentialID != "" {
+			skipped = append(skipped, fmt.Sprintf("certifications[%d].credential_id", i))
+		}
+	}
+
+	for i, p := range r.Publications {
+		url := p.URL
+		if url == "" && p.DOI != "" {
+			url = doiURL + p.DOI
+		} else if p.DOI != "" {
+			skipped = append(skipped, fmt.Sprintf("publications[%d].doi", i))
+		}
+		j.Publications = append(j.Publications, jsonPublication{Name: p.Title, Publisher: p.Venue, ReleaseDate: isoDate(p.Date), URL: url, Summary: p.Description})
+		if len(p.Authors) > 0 {
+			skipped = append(skipped, fmt.Sprintf("publications[%d].authors", i))
+		}
+	}
+
+	for _, a := range r.Awards {
+		j.Awards = append(j.Awards, jsonAward{Title: a.Title, Awarder: a.Awarder, Date: isoDate(a.Date), Summary: a.Description})
+	}
+
+	for i, v := range r.Volunteer {
+		j.Volunteer = append(j.Volunteer, jsonVolunteer{
+			Organization: v.Organization,
+			Position:     v.Position,
+			URL:          v.URL,
+			StartDate:    isoDate(v.StartDate),
+			EndDate:      isoDate(v.EndDate),
+			Highlights:   bulletText(v.Description),
+		})
+		if v.Location != "" {
+			skipped = append(skipped, fmt.Sprintf("volunteer[%d].location", i))
+		}
+	}
+
+	for _, l := range r.Languages {
+		j.Languages = append(j.Languages, jsonLanguage{Language: l.Name, Fluency: l.Level})
+	}
+
+	for _, in := range r.Interests {
+		j.Interests = append(j.Interests, jsonInterest{Name: in.Name, Keywords: in.Keywords})
+	}
+
+	for i, ref := range r.References {
+		j.References = append(j.References, jsonReference{Name: ref.Name, Reference: ref.Text})
+		if ref.Position != "" || ref.Company != "" || ref.Email != "" || ref.Phone.Number != "" {
+			skipped = append(skipped, fmt.Sprintf("references[%d].position/company/email/phone", i))
+		}
+	}
+
+	if !reflect.ValueOf(r.Job).IsZero() {
+		skipped = append(skipped, "job")
+	}
+	if len(r.Custom) > 0 {
+		skipped = append(skipped, "custom")
+	}
+	if r.CoverLetter != (coverLetter{}) {
+		skipped = append(skipped, "cover_letter")
+	}
+	return j, skipped
+}
+
+// jsonEndDate reads the end date of an entry, which JSON Resume leaves out
+// while the entry is ongoing
+func jsonEndDate(start, end string) date {
+	if end == "" && start != "" {
+		return date{text: "Present", ongoing: true}
+	}
+	return parseDate(end)
+}
+
+func isoDate(d date) string {
+	if d.ongoing {
+		return ""
+	}
+	if d.text != "" {
+		return d.text
+	}
+	if d.time.IsZero() {
+		return ""
+	}
+	switch d.precision {
+	case precisionMonth, precisionSeason:
+		return d.time.Format("2006-01")
+	case precisionYear:
+		return d.time.Format("2006")
+	}
+	return d.time.Format("2006-01-02")
+}
+
+func readJSONResume(file string) (resume, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return resume{}, err
+	}
+	var j jsonResume
+	if err := json.Unmarshal(data, &j); err != nil {
+		return resume{}, fmt.Errorf("Error decoding JSON Resume: %w", err)
+	}
+	r, skipped := j.toResume()
+	reportSkipped(file, skipped)
+	return r, nil
+}
+
+func reportSkipped(file string, skipped []string) {
+	for _, s := range skipped {
+		log.Warnf("%s: %s has no equivalent and was not converted", file, s)
+	}
+}
+
+// importJSONResume converts a JSON Resume file into this project's YAML layout
+func importJSONResume(_ config, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: import <resume.json> <resume.yml>")
+	}
+	r, err := readJSONResume(args[0])
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&r); err != nil {
+		return fmt.Errorf("Error marshalling resume: %w", err)
+	}
+	if err := os.WriteFile(args[1], out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("Error writing resume file: %w", err)
+	}
+	log.Infof("Imported %s into %s", args[0], args[1])
+	return nil
+}
+
+// exportJSONResume converts a resume, merged with the base resume if given,
+// into a JSON Resume file. Every entry is exported, whatever tags are selected.
+func exportJSONResume(c config, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: export <resume.yml> <resume.json>")
+	}
+	var r resume
+	if err := r.decodeResume(c.BaseFile, args[0]); err != nil {
+		return err
+	}
+	r.parsePhones(c.Region)
+	if err := r.linkSocials(c); err != nil {
+		return err
+	}
+	j, skipped := r.toJSONResume()
+	reportSkipped(args[0], skipped)
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(j); err != nil {
+		return fmt.Errorf("Error marshalling JSON Resume: %w", err)
+	}
+	if err := os.WriteFile(args[1], out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("Error writing JSON Resume file: %w", err)
+	}
+	log.Infof("Exported %s into %s", args[0], args[1])
+	return nil
+}
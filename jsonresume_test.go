@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testJSONResume = `{
+  "basics": {
+    "name": "Jane Doe",
+    "label": "Engineer",
+    "email": "jane@example.com",
+    "phone": "+44 20 7946 0958",
+    "summary": "Builds things.",
+    "location": {"address": "1 Main St", "postalCode": "12345", "city": "Springfield", "region": "IL", "country": "US"},
+    "profiles": [
+      {"network": "GitHub", "username": "jdoe", "url": "https://www.github.com/jdoe"},
+      {"network": "Forum", "username": "jd", "url": "https://forum.example.com/u/jd"}
+    ]
+  },
+  "work": [
+    {"name": "Acme", "position": "Engineer", "location": "Remote", "description": "Makes anvils", "startDate": "2020-03", "highlights": ["Shipped it"]},
+    {"name": "Initech", "company": "Initech", "position": "Intern", "startDate": "2018", "endDate": "2019-02-15"}
+  ],
+  "education": [
+    {"institution": "State University", "area": "Computer Science", "startDate": "2014-09", "endDate": "2018-05", "score": "3.8", "courses": ["Compilers"]}
+  ],
+  "projects": [
+    {"name": "Ongoing", "highlights": ["Still going"], "keywords": ["Go"], "startDate": "2023-01", "roles": ["Author"]},
+    {"name": "Undated", "url": "https://example.com"},
+    {"name": "Done", "startDate": "2021-01", "endDate": "2021-06", "entity": "Acme", "type": "application"}
+  ],
+  "skills": [{"name": "Languages", "keywords": ["Go", "SQL"]}],
+  "certificates": [{"name": "CKA", "date": "2022-04-01", "issuer": "CNCF"}],
+  "publications": [{"name": "Paper", "publisher": "Journal", "releaseDate": "2021", "url": "https://doi.org/10.1000/182"}],
+  "awards": [{"title": "Prize", "date": "2020-06", "awarder": "Org", "summary": "For work"}],
+  "volunteer": [{"organization": "Club", "position": "Coach", "startDate": "2019-01", "highlights": ["Coached"]}],
+  "languages": [{"language": "French", "fluency": "Fluent"}],
+  "interests": [{"name": "Chess", "keywords": ["Openings"]}],
+  "references": [{"name": "Bob", "reference": "Great"}],
+  "meta": {"version": "1.0"}
+}`
+
+func TestJSONResumeImport(t *testing.T) {
+	var j jsonResume
+	if err := json.Unmarshal([]byte(testJSONResume), &j); err != nil {
+		t.Fatal(err)
+	}
+	r, skipped := j.toResume()
+
+	want := []string{
+		"basics.location.country", "meta", "work[1].company", // keys without a field
+		"basics.label", "work[0].description", "projects[2].entity", "projects[2].type",
+	}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %q, want %q", skipped, want)
+	}
+	if e := r.Experiences[0].EndDate; !e.ongoing {
+		t.Errorf("work without an end date ends on %+v, want ongoing", e)
+	}
+	if e := r.Experiences[1].EndDate; e.precision != precisionDay || e.time.Day() != 15 {
+		t.Errorf("work end date read as %+v", e)
+	}
+	if s := r.Experiences[1].StartDate; s.precision != precisionYear {
+		t.Errorf("a year start date was read with precision %d", s.precision)
+	}
+	if e := r.Projects[0].EndDate; !e.ongoing {
+		t.Errorf("project without an end date ends on %+v, want ongoing", e)
+	}
+	if e := r.Projects[1].EndDate; !e.IsZero() {
+		t.Errorf("project without dates ends on %+v, want no end date", e)
+	}
+	if p := r.Publications[0]; p.DOI != "10.1000/182" || p.URL != "" {
+		t.Errorf("DOI link read as DOI %q and URL %q", p.DOI, p.URL)
+	}
+	if s := r.Info.Socials; s[0].URL != "" || s[1].URL == "" {
+		t.Errorf("profile URLs read as %q and %q, want only the one the platform does not build", s[0].URL, s[1].URL)
+	}
+}
+
+func TestJSONResumeRoundTrip(t *testing.T) {
+	var in jsonResume
+	if err := json.Unmarshal([]byte(testJSONResume), &in); err != nil {
+		t.Fatal(err)
+	}
+	r, _ := in.toResume()
+	// Export reads a loaded resume, whose profiles are linked
+	if err := r.linkSocials(config{}); err != nil {
+		t.Fatal(err)
+	}
+	out, skipped := r.toJSONResume()
+	if len(skipped) != 0 {
+		t.Errorf("export skipped %q", skipped)
+	}
+
+	// What import reports as skipped cannot come back
+	want := in
+	want.Schema = jsonResumeSchema
+	want.unknown = nil
+	want.Basics.Label = ""
+	want.Work[0].Description = ""
+	want.Projects[2].Entity, want.Projects[2].Type = "", ""
+	if !reflect.DeepEqual(out, want) {
+		got, _ := json.MarshalIndent(out, "", "  ")
+		exp, _ := json.MarshalIndent(want, "", "  ")
+		t.Errorf("round trip changed the resume:\ngot  %s\nwant %s", got, exp)
+	}
+}
+
+func TestJSONResumeExportUnfiltered(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "resume.yml")
+	out := filepath.Join(dir, "resume.json")
+	// The job tags of the resume would drop every experience
+	if err := os.WriteFile(in, []byte(testTaggedResume), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := exportJSONResume(config{Tags: "design", ExcludeUntagged: true}, []string{in, out}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var j jsonResume
+	if err := json.Unmarshal(data, &j); err != nil {
+		t.Fatal(err)
+	}
+	companies := names(j.Work, func(w jsonWork) string { return w.Name })
+	if want := []string{"Acme", "Studio", "Globex", "Globex", "Hooli"}; !reflect.DeepEqual(companies, want) {
+		t.Errorf("exported work = %q, want %q", companies, want)
+	}
+	if len(j.Projects) != 2 {
+		t.Errorf("exported %d projects, want 2", len(j.Projects))
+	}
+}
+
+func TestIsoDate(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"2024-05-17", "2024-05-17"},
+		{"2024-05", "2024-05"},
+		{"2024", "2024"},
+		{"Summer 2024", "2024-06"},
+		{"Present", ""},
+		{"", ""},
+		{"TBD", "TBD"},
+	}
+	for _, tt := range tests {
+		if got := isoDate(parseDate(tt.in)); got != tt.want {
+			t.Errorf("isoDate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -3,12 +3,13 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"flag"
 	"fmt"
-	"html/template"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,22 +19,22 @@ import (
 	"github.com/fsnotify/fsnotify"
 )
 
-const (
-	githubURL   = "https://www.github.com"
-	linkedinURL = "https://www.linkedin.com"
-)
+// command is a subcommand that runs instead of generating a resume
+type command struct {
+	usage  string
+	desc   string
+	config bool // whether the configuration file must be loaded first
+	run    func(c config, args []string) error
+}
 
-var dependencies = []string{"pdflatex"}
-
-// TODO: Read from config file instead of hardcoding
-var mapping = map[rune]string{
-	'e': "Education",
-	'x': "Experience",
-	'p': "Projects",
-	's': "Skills",
-	'c': "Certifications",
-	't': "Custom",
-	'm': "Summary",
+var commands = map[string]command{
+	"import":    {usage: "import <resume.json> <resume.yml>", desc: "Convert a JSON Resume (jsonresume.org) file into a resume YAML file", run: importJSONResume},
+	"validate":  {usage: "validate [-b base.yml] [resume.yml...]", desc: "Check the configuration and resume files against the schemas", run: validateCommand},
+	"export":    {usage: "export <resume.yml> <resume.json>", desc: "Convert a resume YAML file, merged with the base resume, into a JSON Resume file", config: true, run: exportJSONResume},
+	"templates": {usage: "templates list", desc: "List the template packs that can be selected with -pack", run: templatesCommand},
+	"init":      {usage: "init [dir]", desc: "Write the built-in templates to a directory (default templates) to customize them", run: initCommand},
+	"schemas":   {usage: "schemas [check]", desc: "Regenerate the JSON schemas from the Go structs, or check that they are up to date", run: schemasCommand},
+	"lint":      {usage: "lint <resume.yml>", desc: "Report the duration of every job and school, the total experience, and the gaps and overlaps of the timeline", config: true, run: lintCommand},
 }
 
 func main() {
@@ -46,6 +47,8 @@ func main() {
 		reload       bool
 		logLevel     string
 		resFile      string
+		explainMerge bool
+		noValidate   bool
 	)
 
 	flag.StringVar(&logLevel, "l", "error", "Set the log level: debug, info, warn, error")
@@ -53,9 +56,14 @@ func main() {
 	flag.BoolVar(&updateConfig, "config", false, "Update the current configuration file")
 	flag.StringVar(&p.BaseFile, "b", "", "The resume that will be used as a basis for missing information")
 	flag.StringVar(&resFile, "f", "", "The YAML file containing resume data")
-	flag.StringVar(&p.TemplateDir, "templates", "", "The directory containing resume templates")
+	flag.BoolVar(&noValidate, "no-validate", false, "Build even if the configuration or resume files do not match the schemas")
+	flag.BoolVar(&explainMerge, "explain-merge", false, "Show which resume file every value came from after merging with the base resume")
+	flag.StringVar(&p.TemplateDir, "templates", "", "The directory containing resume templates. Leave empty to use the built-in templates")
+	flag.StringVar(&p.Pack, "pack", "", "The name of the template pack to use. Leave empty to use the built-in pack")
+	flag.StringVar(&p.PackDir, "packs", "", "The directory containing the installed template packs")
 	flag.StringVar(&p.TexDir, "tex", "tex", "The directory where TeX files will be generated. Leave empty to auto create ./tex directory")
 	flag.StringVar(&p.PdfDir, "dir", "pdf", "The directory where PDF files will be saved. Leave empty to auto create ./pdf directory")
+	flag.StringVar(&p.LogDir, "logs", "", "The directory where the TeX log is kept when a build fails. Leave empty to use the TeX directory")
 	flag.StringVar(&p.KanbanFile, "k", "", "The Markdown file for your Kanban board")
 	flag.StringVar(&p.CoverFile, "cvr", "", "The name of the generated cover letter file. Default option will autogenerate the name")
 	flag.StringVar(&p.PdfFile, "pdf", "", "The name of the generated PDF file. Default option will autogenerate the name")
@@ -63,6 +71,33 @@ func main() {
 	flag.BoolVar(&p.Cover, "c", false, "Generate a Cover Letter?")
 	flag.BoolVar(&p.Track, "t", false, "Whether to track changes in Obsidian?")
 	flag.BoolVar(&p.Show, "s", false, "Show PDF after creation?")
+	flag.StringVar(&p.Engine, "engine", "", "The TeX engine, by default the one of the template pack: "+strings.Join(engineNames(), ", "))
+	flag.BoolVar(&p.HTML, "html", false, "Generate an HTML resume alongside the PDF?")
+	flag.StringVar(&p.Tags, "tags", "", "Comma separated tags used to select bullets and entries, e.g. backend,go")
+	flag.BoolVar(&p.ExcludeUntagged, "exclude-untagged", false, "Exclude entries without tags when filtering by tags?")
+	flag.IntVar(&p.GapMonths, "gap", 0, "The shortest gap in months reported by lint, 3 by default")
+	flag.StringVar(&p.Region, "region", "", "Two letter country code of the phone numbers written without a country code, US when empty")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [command] [flags] [args]\n\nCommands:\n", filepath.Base(os.Args[0]))
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(flag.CommandLine.Output(), "  %s\n\t%s\n", commands[name].usage, commands[name].desc)
+		}
+		fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+		flag.PrintDefaults()
+	}
+
+	var cmdName string
+	if len(os.Args) > 1 {
+		if _, ok := commands[os.Args[1]]; ok {
+			cmdName = os.Args[1]
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		}
+	}
 	flag.Parse()
 
 	switch strings.ToLower(logLevel) {
@@ -79,10 +114,12 @@ func main() {
 		log.SetLevel(log.ErrorLevel)
 	}
 
-	dep := checkDependencies(dependencies)
-	if dep != nil {
-		log.Errorf("Missing dependencies: %v", dep)
-		log.Fatal("Unable to run the program due to missing dependencies")
+	cmd, isCmd := commands[cmdName]
+	if isCmd && !cmd.config {
+		if err := cmd.run(p, flag.Args()); err != nil {
+			log.Fatalf("Error running %s: %v", cmdName, err)
+		}
+		return
 	}
 
 	if updateConfig {
@@ -92,7 +129,11 @@ func main() {
 			log.Fatalf("Error generating configuration file: %v", err)
 		}
 	} else {
-		err := c.readConfig(configFile)
+		err := c.readConfig(configFile, !noValidate)
+		var schemaErrs schemaErrors
+		if errors.As(err, &schemaErrs) {
+			log.Fatalf("%v\nPass -no-validate to run anyway", err)
+		}
 		if err != nil {
 			var genConfig bool
 			log.Warnf("Error reading configuration file: %v", err)
@@ -129,9 +170,33 @@ func main() {
 		log.Fatalf("Error validating configuration: %v", er)
 	}
 
-	if _, err := os.Stat(c.TemplateDir); os.IsNotExist(err) || c.TemplateDir == "" {
+	if isCmd {
+		if err := cmd.run(c, flag.Args()); err != nil {
+			log.Fatalf("Error running %s: %v", cmdName, err)
+		}
+		return
+	}
+
+	if _, err := os.Stat(c.TemplateDir); os.IsNotExist(err) && c.TemplateDir != "" {
 		log.Fatalf("Template directory does not exist: %s", c.TemplateDir)
 	}
+	pack, err := c.loadPack()
+	if err != nil {
+		log.Fatalf("Error loading template pack: %v", err)
+	}
+	if c.Engine == "" {
+		c.Engine = pack.engine()
+	}
+
+	engine, err := getEngine(c.Engine)
+	if err != nil {
+		log.Fatalf("Error selecting TeX engine: %v", err)
+	}
+	dep := checkDependencies([]string{engine.bin})
+	if dep != nil {
+		log.Errorf("Missing dependencies: %v", dep)
+		log.Fatal("Unable to run the program due to missing dependencies")
+	}
 	if _, err := os.Stat(c.TexDir); os.IsNotExist(err) {
 		log.Warnf("Tex directory does not exist: %s", c.TexDir)
 		if err := os.MkdirAll(c.TexDir, 0755); err != nil {
@@ -195,30 +260,45 @@ func main() {
 	var res resume
 	if c.BaseFile == "" {
 		log.Warnf("No base resume file provided. Skipping base resume")
-	} else {
-		err := res.parseResume(c.BaseFile)
-		if err != nil {
-			log.Fatalf("Error parsing resume file: %s - %v", c.BaseFile, err)
-		}
 	}
 
-	err := res.parseResume(resFile)
+	doc, err := loadResumeDoc(c.BaseFile, resFile)
 	if err != nil {
-		log.Fatalf("Error parsing resume file: %s - %v", resFile, err)
+		log.Fatal(err)
 	}
-
-	err = res.sanitizeResume()
+	if explainMerge {
+		doc.explain(os.Stdout)
+	}
+	if !noValidate {
+		if err := validateResume(doc, c.Cover, c.Region); err != nil {
+			log.Fatalf("Resume does not match the schema:\n%v\nPass -no-validate to build anyway", err)
+		}
+	}
+	err = doc.decode(&res)
 	if err != nil {
-		log.Fatal("Error sanitizing resume file:", err)
+		log.Fatalf("Error decoding resume: %v", err)
+	}
+	res.filterTags(c)
+	res.parsePhones(c.Region)
+	if err := res.linkSocials(c); err != nil {
+		log.Fatalf("Error in social platforms: %v", err)
+	}
+	if err := res.applyTheme(c); err != nil {
+		log.Fatalf("Error in theme: %v", err)
 	}
+	pack.checkPackages(res.Theme)
 
+	sections, err := c.registry()
+	if err == nil {
+		sections, err = sections.withCustom(res.Custom)
+	}
+	if err != nil {
+		log.Fatalf("Error in sections: %v", err)
+	}
 	c.Order = strings.ToLower(c.Order)
 
 	if c.Order == "all" {
-		c.Order = ""
-		for k := range mapping {
-			c.Order += string(k)
-		}
+		c.Order = sections.codes()
 	}
 
 	if c.Order == "none" || c.Order == "" {
@@ -226,24 +306,20 @@ func main() {
 		huh.NewInput().
 			Title("Order of Sections").
 			DescriptionFunc(func() string {
-				var desc string
-				for k, v := range mapping {
-					desc += fmt.Sprintf("[%c]%s\t", k, v)
-				}
-				return desc
+				return sections.describe("\t")
 			}, "").
 			Placeholder("expstc").
-			//TODO: Add validation for order
-			// Validate(func(str string) error {
-			// 	reg := regexp.MustCompile()
-			// 	if !reg.MatchString(str) {
-			// 		return fmt.Errorf("invalid order")
-			// 	}
-			// 	return nil
-			// }).
+			Validate(func(str string) error {
+				_, err := sections.parseOrder(str)
+				return err
+			}).
 			Value(&c.Order).
 			Run()
 	}
+	order, err := sections.parseOrder(c.Order)
+	if err != nil {
+		log.Fatalf("Error in section order: %v", err)
+	}
 
 	resumeName := getFilename(resFile)
 	name := strings.ReplaceAll(res.Info.Name, " ", "_")
@@ -255,25 +331,33 @@ func main() {
 		c.CoverFile = fmt.Sprintf("%s_%s_cvr", name, resumeName)
 	}
 
-	err = res.execTmpl(c.TemplateDir, c.TexDir, c.PdfFile, c.Order, "resume", true)
+	err = res.execTmpl(pack, c.TexDir, c.PdfFile, order, "resume", true)
 	if err != nil {
 		log.Fatalf("Error executing templates: %v", err)
 	}
 
-	err = generatePDF(c.TexDir, c.PdfDir, c.PdfFile)
+	err = res.generatePDF(c, c.PdfFile)
 	if err != nil {
 		log.Fatalf("Error generating PDF: %v", err)
 	}
 	log.Infof("Generated PDF: %s", c.PdfFile)
 
+	if c.HTML {
+		err = res.execTmpl(pack, c.PdfDir, c.PdfFile, order, "html", true)
+		if err != nil {
+			log.Fatalf("Error generating HTML: %v", err)
+		}
+		log.Infof("Generated HTML: %s", c.PdfFile)
+	}
+
 	if c.Cover {
-		err = res.execTmpl(c.TemplateDir, c.TexDir, c.CoverFile, "", "cover", true)
+		err = res.execTmpl(pack, c.TexDir, c.CoverFile, nil, "cover", true)
 		if err != nil {
 			log.Fatalf("Error executing cover letter template: %v", err)
 		}
 		log.Infof("Generated cover letter TeX file: %s", c.CoverFile)
 
-		err = generatePDF(c.TexDir, c.PdfDir, c.CoverFile)
+		err = res.generatePDF(c, c.CoverFile)
 		if err != nil {
 			log.Fatalf("Error generating cover letter: %v", err)
 		}
@@ -296,10 +380,10 @@ func main() {
 		//FIXME: Test Obsidian tracking
 		log.Warnf("Tracking PDF in Obsidian is not fully tested yet. Expect bugs")
 		var mdBuff bytes.Buffer
-		tmplFuncs := template.FuncMap{
-			"today": func() string { return time.Now().Format("2006-01-02") },
+		md, err := parseTemplates(pack, sectionFuncs{}, "markdown")
+		if err != nil {
+			log.Fatalf("Error parsing Obsidian template: %v", err)
 		}
-		md, err := template.New("obsidian").Funcs(tmplFuncs).ParseGlob(path.Join(c.TemplateDir, "*.tmpl"))
 		err = md.ExecuteTemplate(&mdBuff, "obsidian", res)
 		if err != nil {
 			log.Fatalf("Error executing Obsidian template: %v", err)
@@ -401,31 +485,41 @@ func main() {
 							if e.Op.Has(fsnotify.Write) {
 								log.Debugf("File modified: %s", e.Name)
 								time.Sleep(1 * time.Second)
-								res.parseResume(resFile)
-								log.Debugf("Parsed resume file: %s", resFile)
-								err = res.sanitizeResume()
-								if err != nil {
-									log.Fatalf("Error sanitizing resume file: %v", err)
+								res = resume{}
+								if err := res.loadResume(c, resFile); err != nil {
+									log.Errorf("Error reloading resume: %v", err)
+									continue
 								}
-								log.Debugf("Sanitized resume file: %s", resFile)
-								err = res.execTmpl(c.TemplateDir, c.TexDir, c.PdfFile, c.Order, "resume", false)
+								if err := res.applyTheme(c); err != nil {
+									log.Errorf("Error in theme: %v", err)
+									continue
+								}
+								log.Debugf("Parsed resume file: %s", resFile)
+								err = res.execTmpl(pack, c.TexDir, c.PdfFile, order, "resume", false)
 								if err != nil {
 									log.Fatalf("Error executing templates: %v", err)
 								}
 								log.Infof("Generated TeX file: %s", c.PdfFile)
-								err = generatePDF(c.TexDir, c.PdfDir, c.PdfFile)
+								err = res.generatePDF(c, c.PdfFile)
 								if err != nil {
 									log.Fatalf("Error generating PDF: %v", err)
 								}
 								log.Infof("Generated PDF: %s", c.PdfFile)
 								openFile(path.Join(c.PdfDir, c.PdfFile+".pdf"))
+								if c.HTML {
+									err = res.execTmpl(pack, c.PdfDir, c.PdfFile, order, "html", false)
+									if err != nil {
+										log.Fatalf("Error generating HTML: %v", err)
+									}
+									log.Infof("Generated HTML: %s", c.PdfFile)
+								}
 								if c.Cover {
-									err = res.execTmpl(c.TemplateDir, c.TexDir, c.CoverFile, "", "cover", false)
+									err = res.execTmpl(pack, c.TexDir, c.CoverFile, nil, "cover", false)
 									if err != nil {
 										log.Fatalf("Error executing cover letter template: %v", err)
 									}
 									log.Infof("Generated cover letter TeX file: %s", c.CoverFile)
-									err = generatePDF(c.TexDir, c.PdfDir, c.CoverFile)
+									err = res.generatePDF(c, c.CoverFile)
 									if err != nil {
 										log.Fatalf("Error generating cover letter: %v", err)
 									}
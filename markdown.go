@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+	"unicode"
+)
+
+// Inline Markdown supported in the text fields tagged sanitize:"markdown":
+// **bold**, __bold__, *italic*, _italic_, `code` and [text](url).
+// A backslash before a marker keeps it literal.
+
+type mdKind int
+
+const (
+	mdText mdKind = iota
+	mdBold
+	mdItalic
+	mdCode
+	mdLink
+)
+
+// mdNode is a span of inline Markdown
+type mdNode struct {
+	kind     mdKind
+	text     string // content of text and code spans
+	url      string // target of links
+	children []mdNode
+}
+
+// parseMarkdown splits a string into inline Markdown spans. Markers that are
+// not closed are kept as text.
+func parseMarkdown(s string) []mdNode {
+	var nodes []mdNode
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, mdNode{kind: mdText, text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\*_`[]()", s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end > 0 {
+				flush()
+				nodes = append(nodes, mdNode{kind: mdCode, text: s[i+1 : i+1+end]})
+				i += end + 2
+				continue
+			}
+		case (c == '*' || c == '_') && strings.HasPrefix(s[i:], strings.Repeat(string(c), 2)) && canOpen(s, i):
+			delim := s[i : i+2]
+			if end := closing(s, i+2, delim); end > 0 {
+				flush()
+				nodes = append(nodes, mdNode{kind: mdBold, children: parseMarkdown(s[i+2 : end])})
+				i = end + 2
+				continue
+			}
+		case (c == '*' || c == '_') && canOpen(s, i):
+			if end := closing(s, i+1, string(c)); end > 0 {
+				flush()
+				nodes = append(nodes, mdNode{kind: mdItalic, children: parseMarkdown(s[i+1 : end])})
+				i = end + 1
+				continue
+			}
+		case c == '[':
+			if mid := strings.Index(s[i:], "]("); mid > 0 {
+				if end := closeParen(s[i+mid+2:]); end > 0 {
+					flush()
+					nodes = append(nodes, mdNode{
+						kind:     mdLink,
+						url:      s[i+mid+2 : i+mid+2+end],
+						children: parseMarkdown(s[i+1 : i+mid]),
+					})
+					i += mid + 2 + end + 1
+					continue
+				}
+			}
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return nodes
+}
+
+// canOpen reports whether the marker at i can start a span: it must be
+// followed by text, and an underscore must not be inside a word (snake_case)
+func canOpen(s string, i int) bool {
+	n := 1
+	if i+1 < len(s) && s[i+1] == s[i] {
+		n = 2
+	}
+	if i+n >= len(s) || s[i+n] == ' ' {
+		return false
+	}
+	return s[i] != '_' || i == 0 || !isWordByte(s[i-1])
+}
+
+// closeParen returns the index of the parenthesis closing a link target,
+// allowing balanced parentheses inside it, or -1
+func closeParen(s string) int {
+	depth := 0
+	for j := 0; j < len(s); j++ {
+		switch s[j] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return j
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// closing returns the index of the delimiter that closes a span opened
+// before from, or -1
+func closing(s string, from int, delim string) int {
+	for j := from; j+len(delim) <= len(s); j++ {
+		if s[j] == '\\' {
+			j++
+			continue
+		}
+		if s[j] != delim[0] {
+			continue
+		}
+		// *a **b** c*: a single marker is never part of a run of markers
+		run := j
+		for run < len(s) && s[run] == delim[0] {
+			run++
+		}
+		if len(delim) == 1 && run-j > 1 {
+			j = run - 1
+			continue
+		}
+		if !strings.HasPrefix(s[j:], delim) || j == from || s[j-1] == ' ' {
+			continue
+		}
+		if delim[0] == '_' && j+len(delim) < len(s) && isWordByte(s[j+len(delim)]) {
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+func isWordByte(b byte) bool {
+	return b >= 0x80 || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
+
+// markdownLaTeX renders inline Markdown to LaTeX, escaping the text
+func markdownLaTeX(s string) (string, error) {
+	out, err := mdToLaTeX(parseMarkdown(s))
+	if err != nil {
+		return "", err
+	}
+	if err := checkPrimitives(out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func mdToLaTeX(nodes []mdNode) (string, error) {
+	var out strings.Builder
+	for _, n := range nodes {
+		var err error
+		var text, inner string
+		switch n.kind {
+		case mdText, mdCode:
+			text, err = sanitize(n.text)
+		case mdLink:
+			if inner, err = mdToLaTeX(n.children); err == nil {
+				text, err = sanitizeURL(n.url)
+			}
+		default:
+			inner, err = mdToLaTeX(n.children)
+		}
+		if err != nil {
+			return "", err
+		}
+		switch n.kind {
+		case mdText:
+			out.WriteString(text)
+		case mdCode:
+			out.WriteString(`\texttt{` + text + `}`)
+		case mdBold:
+			out.WriteString(`\textbf{` + inner + `}`)
+		case mdItalic:
+			out.WriteString(`\textit{` + inner + `}`)
+		case mdLink:
+			if !safeLink(n.url) {
+				out.WriteString(inner)
+				continue
+			}
+			out.WriteString(`\href{` + text + `}{` + inner + `}`)
+		}
+	}
+	return out.String(), nil
+}
+
+// markdownHTML renders inline Markdown to HTML for the html_ templates
+func markdownHTML(v interface{}) template.HTML {
+	return template.HTML(mdToHTML(parseMarkdown(fmt.Sprint(v))))
+}
+
+func mdToHTML(nodes []mdNode) string {
+	var out strings.Builder
+	for _, n := range nodes {
+		switch n.kind {
+		case mdText:
+			out.WriteString(html.EscapeString(n.text))
+		case mdCode:
+			out.WriteString("<code>" + html.EscapeString(n.text) + "</code>")
+		case mdBold:
+			out.WriteString("<strong>" + mdToHTML(n.children) + "</strong>")
+		case mdItalic:
+			out.WriteString("<em>" + mdToHTML(n.children) + "</em>")
+		case mdLink:
+			if !safeLink(n.url) {
+				out.WriteString(mdToHTML(n.children))
+				continue
+			}
+			out.WriteString(`<a href="` + html.EscapeString(n.url) + `">` + mdToHTML(n.children) + "</a>")
+		}
+	}
+	return out.String()
+}
+
+// safeLink reports whether a link is http(s), mailto or relative; the text of
+// other links is rendered without the link
+func safeLink(url string) bool {
+	scheme, _, found := strings.Cut(strings.ToLower(strings.TrimSpace(url)), ":")
+	return !found || strings.ContainsAny(scheme, "/?#") || scheme == "http" || scheme == "https" || scheme == "mailto"
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+var markdownTests = []struct {
+	name, in    string
+	latex, html string
+}{
+	{"plain", "a & b", `a \& b`, "a &amp; b"},
+	{"bold", "**a**", `\textbf{a}`, "<strong>a</strong>"},
+	{"italic", "_a_ and *b*", `\textit{a} and \textit{b}`, "<em>a</em> and <em>b</em>"},
+	{"bold in italic", "*a **b** c*", `\textit{a \textbf{b} c}`, "<em>a <strong>b</strong> c</em>"},
+	{"italic in bold", "**a *b* c**", `\textbf{a \textit{b} c}`, "<strong>a <em>b</em> c</strong>"},
+	{"underscores nested", "_a __b__ c_", `\textit{a \textbf{b} c}`, "<em>a <strong>b</strong> c</em>"},
+	{"two spans", "*a* and *b*", `\textit{a} and \textit{b}`, "<em>a</em> and <em>b</em>"},
+	{"unclosed italic", "*a b", `*a b`, "*a b"},
+	{"unclosed bold", "**a b", `**a b`, "**a b"},
+	{"unclosed nested", "*a **b c*", `\textit{a **b c}`, "<em>a **b c</em>"},
+	{"spaced marker", "a * b * c", `a * b * c`, "a * b * c"},
+	{"snake case", "snake_case_name", `snake\_case\_name`, "snake_case_name"},
+	{"escaped", `\*a\* \_b\_`, `*a* \_b\_`, "*a* _b_"},
+	{"escaped closer", `*a\*b*`, `\textit{a*b}`, "<em>a*b</em>"},
+	{"code", "`a*b*`", `\texttt{a*b*}`, "<code>a*b*</code>"},
+	{"link", "[**Go**](https://go.dev)", `\href{https://go.dev}{\textbf{Go}}`, `<a href="https://go.dev"><strong>Go</strong></a>`},
+	{"unsafe link", "[x](javascript:alert(1))", "x", "x"},
+}
+
+func TestMarkdownLaTeX(t *testing.T) {
+	for _, tt := range markdownTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := markdownLaTeX(tt.in)
+			if err != nil {
+				t.Fatalf("markdownLaTeX(%q) failed: %v", tt.in, err)
+			}
+			if got != tt.latex {
+				t.Errorf("markdownLaTeX(%q) = %q, want %q", tt.in, got, tt.latex)
+			}
+		})
+	}
+}
+
+func TestMarkdownHTML(t *testing.T) {
+	for _, tt := range markdownTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(markdownHTML(tt.in)); got != tt.html {
+				t.Errorf("markdownHTML(%q) = %q, want %q", tt.in, got, tt.html)
+			}
+		})
+	}
+}
+
+func TestMarkdownFields(t *testing.T) {
+	r := resume{
+		Info:        info{Name: "Jane Doe"},
+		Experiences: []experience{{Company: "**Acme**", Title: "Engineer", Description: []bullet{{Text: "Cut costs by **40%**"}}}},
+		Projects:    []project{{Name: "Site", Description: []string{"Runs [online](https://example.com)"}}},
+	}
+	order, err := sectionRegistry(defaultSections).parseOrder("xp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tmplType string
+		want     []string
+	}{
+		{"resume", []string{`Cut costs by \textbf{40\%}`, `Runs \href{https://example.com}{online}`, `**Acme**`}},
+		{"html", []string{"Cut costs by <strong>40%</strong>", `Runs <a href="https://example.com">online</a>`, "**Acme**"}},
+	}
+	for _, tt := range tests {
+		out := renderTest(t, &r, order, tt.tmplType)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output lacks %q:\n%s", tt.tmplType, want, out)
+			}
+		}
+	}
+	if r.Experiences[0].Description[0].Text != "Cut costs by **40%**" {
+		t.Errorf("rendering changed the bullet to %q", r.Experiences[0].Description[0].Text)
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/log"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// mergeKeys are the fields that identify an entry of a list when the job
+// specific resume is merged into the base resume. Lists that are not keyed
+// are replaced unless a directive says otherwise.
+var mergeKeys = map[string][]string{
+	"information.socials": {"platform"},
+	"education":           {"name", "major"},
+	"experience":          {"company", "title"},
+	"experience.roles":    {"title"},
+	"projects":            {"name"},
+	"skills":              {"name"},
+	"certifications":      {"name"},
+	"publications":        {"title"},
+	"awards":              {"title"},
+	"volunteer":           {"organization", "position"},
+	"languages":           {"name"},
+	"interests":           {"name"},
+	"references":          {"name"},
+	"custom":              {"key"},
+}
+
+// Directives are given as a tag on a list (e.g. `description: !append [...]`)
+// or as a `_merge` key on an entry of a keyed list (e.g. `_merge: remove`).
+const (
+	mergeDirectiveKey = "_merge"
+	directiveMerge    = "merge"
+	directiveAppend   = "append"
+	directivePrepend  = "prepend"
+	directiveReplace  = "replace"
+	directiveRemove   = "remove"
+)
+
+// resumeDoc is the merged YAML document of one or more resume files
+type resumeDoc struct {
+	root   *yaml.Node
+	origin map[*yaml.Node]string // file each node was read from
+	notes  []string              // directives applied while merging
+}
+
+// loadResumeDoc reads and merges the resume files in order, later files taking precedence
+func loadResumeDoc(files ...string) (*resumeDoc, error) {
+	d := &resumeDoc{
+		root:   &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1},
+		origin: map[*yaml.Node]string{},
+	}
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		n, err := parseResumeFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing resume file: %s - %w", file, err)
+		}
+		if n.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("Error parsing resume file: %s - expected a mapping at the top level", file)
+		}
+		d.track(n, path.Base(file))
+		d.origin[d.root] = path.Base(file)
+		if err := d.merge(d.root, n, "", path.Base(file)); err != nil {
+			return nil, fmt.Errorf("Error merging resume file: %s - %w", file, err)
+		}
+	}
+	return d, nil
+}
+
+// decode decodes the merged document into the resume
+func (d *resumeDoc) decode(r *resume) error {
+	return d.root.Decode(r)
+}
+
+func (d *resumeDoc) track(n *yaml.Node, file string) {
+	d.origin[n] = file
+	for _, c := range n.Content {
+		d.track(c, file)
+	}
+}
+
+// merge merges src into dst, which must be a mapping node, in place
+func (d *resumeDoc) merge(dst, src *yaml.Node, at, file string) error {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		k, v := src.Content[i], src.Content[i+1]
+		if k.Value == mergeDirectiveKey {
+			continue
+		}
+		p := joinPath(at, k.Value)
+		idx := mappingIndex(dst, k.Value)
+		if idx < 0 {
+			merged, err := d.mergeValue(nil, v, p, file)
+			if err != nil {
+				return err
+			}
+			dst.Content = append(dst.Content, k, merged)
+			continue
+		}
+		merged, err := d.mergeValue(dst.Content[idx+1], v, p, file)
+		if err != nil {
+			return err
+		}
+		dst.Content[idx+1] = merged
+	}
+	return nil
+}
+
+// mergeValue returns the result of merging src into dst, dst may be nil
+func (d *resumeDoc) mergeValue(dst, src *yaml.Node, at, file string) (*yaml.Node, error) {
+	switch src.Kind {
+	case yaml.MappingNode:
+		if dst == nil || dst.Kind != yaml.MappingNode {
+			dst = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: src.Line, Column: src.Column}
+			d.origin[dst] = file
+		}
+		return dst, d.merge(dst, src, at, file)
+	case yaml.SequenceNode:
+		return d.mergeSequence(dst, src, at, file)
+	}
+	return src, nil
+}
+
+func (d *resumeDoc) mergeSequence(dst, src *yaml.Node, at, file string) (*yaml.Node, error) {
+	directive := ""
+	if strings.HasPrefix(src.Tag, "!") && !strings.HasPrefix(src.Tag, "!!") {
+		directive = strings.TrimPrefix(src.Tag, "!")
+	}
+	src.Tag = "!!seq"
+
+	switch directive {
+	case "", directiveMerge, directiveAppend, directivePrepend, directiveReplace, directiveRemove:
+	default:
+		return nil, fmt.Errorf("line %d: unknown merge directive !%s on %s", src.Line, directive, at)
+	}
+
+	keys, keyed := mergeKeys[at]
+	if directive == "" {
+		directive = directiveReplace
+		if keyed {
+			directive = directiveMerge
+		}
+	}
+
+	// Entries are merged against nothing so their own directives are resolved
+	resolve := func(entries []*yaml.Node) ([]*yaml.Node, error) {
+		var items []*yaml.Node
+		for _, entry := range entries {
+			if scalarValue(entry, mergeDirectiveKey) == directiveRemove {
+				continue
+			}
+			item, err := d.mergeValue(nil, entry, at, file)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	if dst == nil || dst.Kind != yaml.SequenceNode || directive == directiveReplace {
+		items, err := resolve(src.Content)
+		if err != nil {
+			return nil, err
+		}
+		if directive == directiveRemove {
+			items = nil
+		}
+		src.Content = items
+		return src, nil
+	}
+
+	switch directive {
+	case directiveAppend, directivePrepend:
+		items, err := resolve(src.Content)
+		if err != nil {
+			return nil, err
+		}
+		if directive == directiveAppend {
+			dst.Content = append(dst.Content, items...)
+		} else {
+			dst.Content = append(items, dst.Content...)
+		}
+		d.note("%s: %sed %d entries to %s", file, directive, len(items), at)
+	case directiveRemove:
+		for _, entry := range src.Content {
+			before := len(dst.Content)
+			dst.Content = removeMatching(dst.Content, entry, keys)
+			if len(dst.Content) == before {
+				log.Warnf("%s: nothing in %s matched %s to remove", file, at, describe(entry, keys))
+				continue
+			}
+			d.note("%s: removed %s from %s", file, describe(entry, keys), at)
+		}
+	case directiveMerge:
+		if !keyed {
+			return nil, fmt.Errorf("line %d: %s entries have no key to merge on, use !append, !prepend, !replace or !remove", src.Line, at)
+		}
+		for _, entry := range src.Content {
+			action := scalarValue(entry, mergeDirectiveKey)
+			idx := findMatching(dst.Content, entry, keys)
+			switch {
+			case action == directiveRemove:
+				if idx < 0 {
+					log.Warnf("%s: nothing in %s matched %s to remove", file, at, describe(entry, keys))
+					continue
+				}
+				dst.Content = append(dst.Content[:idx], dst.Content[idx+1:]...)
+				d.note("%s: removed %s from %s", file, describe(entry, keys), at)
+			case action != "" && action != directiveMerge && action != directiveReplace:
+				return nil, fmt.Errorf("line %d: unknown %s directive %q", entry.Line, mergeDirectiveKey, action)
+			case idx < 0 || action == directiveReplace:
+				items, err := resolve([]*yaml.Node{entry})
+				if err != nil {
+					return nil, err
+				}
+				if idx < 0 {
+					dst.Content = append(dst.Content, items...)
+					continue
+				}
+				dst.Content[idx] = items[0]
+				d.note("%s: replaced %s in %s", file, describe(entry, keys), at)
+			default:
+				if err := d.merge(dst.Content[idx], entry, at, file); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return dst, nil
+}
+
+func (d *resumeDoc) note(format string, args ...any) {
+	d.notes = append(d.notes, fmt.Sprintf(format, args...))
+}
+
+// explain writes which file every value of the merged resume came from
+func (d *resumeDoc) explain(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tSOURCE")
+	var walk func(n *yaml.Node, at string)
+	walk = func(n *yaml.Node, at string) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				walk(n.Content[i+1], joinPath(at, n.Content[i].Value))
+			}
+		case yaml.SequenceNode:
+			if len(n.Content) == 0 {
+				fmt.Fprintf(tw, "%s\t%s\n", at, d.origin[n])
+			}
+			for i, c := range n.Content {
+				walk(c, fmt.Sprintf("%s[%d]", at, i))
+			}
+		default:
+			fmt.Fprintf(tw, "%s\t%s\n", at, d.origin[n])
+		}
+	}
+	walk(d.root, "")
+	tw.Flush()
+	for _, n := range d.notes {
+		fmt.Fprintln(w, n)
+	}
+}
+
+func joinPath(at, key string) string {
+	if at == "" {
+		return key
+	}
+	return at + "." + key
+}
+
+func mappingIndex(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func scalarValue(n *yaml.Node, key string) string {
+	if n.Kind != yaml.MappingNode {
+		return ""
+	}
+	if i := mappingIndex(n, key); i >= 0 {
+		return strings.ToLower(strings.TrimSpace(n.Content[i+1].Value))
+	}
+	return ""
+}
+
+// entryKey identifies an entry by its key fields, or by its value for scalars
+func entryKey(n *yaml.Node, keys []string) string {
+	if n.Kind == yaml.ScalarNode {
+		return strings.ToLower(strings.TrimSpace(n.Value))
+	}
+	if len(keys) == 0 {
+		keys = []string{"text"}
+	}
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, scalarValue(n, k))
+	}
+	key := strings.Join(parts, "/")
+	if strings.Trim(key, "/") == "" {
+		return ""
+	}
+	return key
+}
+
+func findMatching(items []*yaml.Node, n *yaml.Node, keys []string) int {
+	key := entryKey(n, keys)
+	if key == "" {
+		return -1
+	}
+	for i, item := range items {
+		if entryKey(item, keys) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeMatching(items []*yaml.Node, n *yaml.Node, keys []string) []*yaml.Node {
+	key := entryKey(n, keys)
+	kept := items[:0]
+	for _, item := range items {
+		if key == "" || entryKey(item, keys) != key {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func describe(n *yaml.Node, keys []string) string {
+	return fmt.Sprintf("%q", entryKey(n, keys))
+}
+
+// parseResumeFile reads a YAML or JSON Resume file into a YAML node
+func parseResumeFile(resumeFile string) (*yaml.Node, error) {
+	var node yaml.Node
+	if strings.EqualFold(path.Ext(resumeFile), ".json") {
+		imported, err := readJSONResume(resumeFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := node.Encode(&imported); err != nil {
+			return nil, err
+		}
+		log.Infof("Parsed JSON Resume file: %s", resumeFile)
+		return &node, nil
+	}
+	f, err := os.Open(resumeFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if err := yaml.NewDecoder(f).Decode(&node); err != nil && err != io.EOF {
+		return nil, err
+	}
+	log.Infof("Parsed resume file: %s", resumeFile)
+	if node.Kind == yaml.DocumentNode {
+		return node.Content[0], nil
+	}
+	if node.Kind == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	return &node, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testBaseResume = `information:
+  name: Jane Doe
+  email: jane@example.com
+  socials:
+    - platform: GitHub
+      username: jdoe
+    - platform: LinkedIn
+      username: janedoe
+experience:
+  - company: Acme
+    title: Engineer
+    location: Remote
+    description:
+      - Built the API
+      - Ran the on-call rotation
+  - company: Initech
+    title: Intern
+    description:
+      - Filed reports
+skills:
+  - name: Languages
+    keywords: [Go, SQL]
+  - name: Tools
+    keywords: [Git]
+`
+
+// loadTestDoc merges the job resume into the base resume
+func loadTestDoc(t *testing.T, job string) (*resumeDoc, error) {
+	t.Helper()
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yml")
+	file := filepath.Join(dir, "job.yml")
+	if err := os.WriteFile(base, []byte(testBaseResume), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(job), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return loadResumeDoc(base, file)
+}
+
+func mergeTestResume(t *testing.T, job string) resume {
+	t.Helper()
+	doc, err := loadTestDoc(t, job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r resume
+	if err := doc.decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestMergeKeyed(t *testing.T) {
+	r := mergeTestResume(t, `information:
+  email: jane@work.example
+  socials:
+    - platform: GitHub
+      username: jane-work
+experience:
+  - company: Acme
+    title: Engineer
+    location: Berlin
+  - company: Globex
+    title: Lead
+`)
+	if r.Info.Name != "Jane Doe" || r.Info.Email != "jane@work.example" {
+		t.Errorf("information merged into %q <%s>", r.Info.Name, r.Info.Email)
+	}
+	if len(r.Info.Socials) != 2 || r.Info.Socials[0].Username != "jane-work" || r.Info.Socials[1].Username != "janedoe" {
+		t.Errorf("socials merged into %+v", r.Info.Socials)
+	}
+	var companies []string
+	for _, e := range r.Experiences {
+		companies = append(companies, e.Company)
+	}
+	if want := []string{"Acme", "Initech", "Globex"}; !reflect.DeepEqual(companies, want) {
+		t.Errorf("experiences = %q, want %q", companies, want)
+	}
+	acme := r.Experiences[0]
+	if acme.Location != "Berlin" || len(acme.Description) != 2 {
+		t.Errorf("Acme merged into %q with %d bullets, want Berlin and the base bullets", acme.Location, len(acme.Description))
+	}
+}
+
+func TestMergeDirectives(t *testing.T) {
+	tests := []struct {
+		name, job string
+		want      []string
+	}{
+		{"replace by default", `experience:
+  - company: Acme
+    title: Engineer
+    description: [Wrote Go]
+`, []string{"Wrote Go"}},
+		{"append", `experience:
+  - company: Acme
+    title: Engineer
+    description: !append [Wrote Go]
+`, []string{"Built the API", "Ran the on-call rotation", "Wrote Go"}},
+		{"prepend", `experience:
+  - company: Acme
+    title: Engineer
+    description: !prepend [Wrote Go]
+`, []string{"Wrote Go", "Built the API", "Ran the on-call rotation"}},
+		{"remove", `experience:
+  - company: Acme
+    title: Engineer
+    description: !remove [ran the on-call rotation]
+`, []string{"Built the API"}},
+		{"replace entry", `experience:
+  - company: Acme
+    title: Engineer
+    _merge: replace
+    description: [Only this]
+`, []string{"Only this"}},
+	}
+	for _, tt := range tests {
+		r := mergeTestResume(t, tt.job)
+		if got := bulletText(r.Experiences[0].Description); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: description = %q, want %q", tt.name, got, tt.want)
+		}
+		if tt.name == "replace entry" && r.Experiences[0].Location != "" {
+			t.Errorf("%s: the replaced entry kept the location %q", tt.name, r.Experiences[0].Location)
+		}
+	}
+}
+
+func TestMergeRemoveEntries(t *testing.T) {
+	r := mergeTestResume(t, `experience:
+  - company: Initech
+    title: Intern
+    _merge: remove
+skills: !remove
+  - name: Tools
+`)
+	if len(r.Experiences) != 1 || r.Experiences[0].Company != "Acme" {
+		t.Errorf("experiences = %+v, want only Acme", r.Experiences)
+	}
+	if len(r.Skills) != 1 || r.Skills[0].Name != "Languages" {
+		t.Errorf("skills = %+v, want only Languages", r.Skills)
+	}
+}
+
+func TestMergeErrors(t *testing.T) {
+	tests := []struct {
+		name, job, want string
+	}{
+		{"unknown tag", "skills: !shuffle []\n", "unknown merge directive !shuffle"},
+		{"unknown entry directive", "skills:\n  - name: Tools\n    _merge: drop\n", `unknown _merge directive "drop"`},
+		{"merge without keys", "experience:\n  - company: Acme\n    title: Engineer\n    description: !merge [x]\n", "have no key to merge on"},
+	}
+	for _, tt := range tests {
+		_, err := loadTestDoc(t, tt.job)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestMergeExplain(t *testing.T) {
+	doc, err := loadTestDoc(t, `information:
+  email: jane@work.example
+skills: !append
+  - name: Cloud
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	doc.explain(&out)
+	sources := map[string]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if f := strings.Fields(line); len(f) == 2 {
+			sources[f[0]] = f[1]
+		}
+	}
+	for field, want := range map[string]string{
+		"information.name":  "base.yml",
+		"information.email": "job.yml",
+		"skills[1].name":    "base.yml",
+		"skills[2].name":    "job.yml",
+	} {
+		if sources[field] != want {
+			t.Errorf("%s comes from %q, want %s", field, sources[field], want)
+		}
+	}
+	if !strings.Contains(out.String(), "job.yml: appended 1 entries to skills") {
+		t.Errorf("explain output lacks the append note:\n%s", out.String())
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// defaultRegion is the region of numbers written without a country code when
+// none is configured, matching the US-only formatting of earlier versions
+const defaultRegion = "US"
+
+// phoneCountry is the numbering plan of a country, detailed enough to check
+// the length of a number. Area codes vary in length within most countries, so
+// numbers are shown grouped the way they were written.
+type phoneCountry struct {
+	region   string // ISO 3166-1 alpha-2 code
+	code     string // country calling code
+	trunk    string // prefix dialed before national numbers, dropped in international format
+	min, max int    // length of the national significant number
+}
+
+// nanp is the North American Numbering Plan, formatted as (123) 456-7890
+const nanp = "1"
+
+var phoneCountries = []phoneCountry{
+	{"US", nanp, "", 10, 10},
+	{"CA", nanp, "", 10, 10},
+	{"GB", "44", "0", 9, 10},
+	{"IE", "353", "0", 7, 9},
+	{"FR", "33", "0", 9, 9},
+	{"DE", "49", "0", 6, 11},
+	{"ES", "34", "", 9, 9},
+	{"IT", "39", "", 6, 11},
+	{"PT", "351", "", 9, 9},
+	{"NL", "31", "0", 9, 9},
+	{"BE", "32", "0", 8, 9},
+	{"CH", "41", "0", 9, 9},
+	{"AT", "43", "0", 4, 13},
+	{"SE", "46", "0", 7, 9},
+	{"NO", "47", "", 8, 8},
+	{"DK", "45", "", 8, 8},
+	{"FI", "358", "0", 5, 12},
+	{"PL", "48", "", 9, 9},
+	{"IN", "91", "0", 10, 10},
+	{"PK", "92", "0", 9, 10},
+	{"CN", "86", "0", 10, 11},
+	{"JP", "81", "0", 9, 10},
+	{"KR", "82", "0", 9, 10},
+	{"SG", "65", "", 8, 8},
+	{"AU", "61", "0", 9, 9},
+	{"NZ", "64", "0", 8, 10},
+	{"ZA", "27", "0", 9, 9},
+	{"NG", "234", "0", 8, 10},
+	{"BR", "55", "0", 10, 11},
+	{"MX", "52", "", 10, 10},
+	{"AE", "971", "0", 8, 9},
+	{"IL", "972", "0", 8, 9},
+}
+
+var (
+	phoneExtRe   = regexp.MustCompile(`(?i)\s*(?:ext\.?|x|#)\s*(\d+)$`)
+	phoneCharsRe = regexp.MustCompile(`^\+?[0-9 ().\-/]+$`)
+)
+
+func findRegion(region string) (phoneCountry, bool) {
+	for _, c := range phoneCountries {
+		if strings.EqualFold(c.region, region) {
+			return c, true
+		}
+	}
+	return phoneCountry{}, false
+}
+
+// findCallingCode returns the country whose calling code starts the digits
+func findCallingCode(digits string) (phoneCountry, bool) {
+	for n := 3; n >= 1; n-- {
+		if len(digits) <= n {
+			continue
+		}
+		for _, c := range phoneCountries {
+			if c.code == digits[:n] {
+				return c, true
+			}
+		}
+	}
+	return phoneCountry{}, false
+}
+
+func regionNames() []string {
+	var names []string
+	for _, c := range phoneCountries {
+		names = append(names, c.region)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parsePhone reads a number with or without a country code, separated by
+// spaces, dashes, dots, slashes or parentheses. Numbers without a country
+// code belong to the region. It returns the E.164 form used in links and the
+// display form, national when the number is from the region and
+// international otherwise.
+func parsePhone(number, region string) (e164, display string, err error) {
+	if region == "" {
+		region = defaultRegion
+	}
+	home, ok := findRegion(region)
+	if !ok {
+		return "", "", fmt.Errorf("unknown phone region %q, expected one of: %s", region, strings.Join(regionNames(), ", "))
+	}
+
+	s := strings.TrimSpace(number)
+	var ext string
+	if m := phoneExtRe.FindStringSubmatchIndex(s); m != nil {
+		ext, s = s[m[2]:m[3]], s[:m[0]]
+	}
+	if !phoneCharsRe.MatchString(s) {
+		return "", "", fmt.Errorf("only digits, a leading + and the separators ( ) . - / are allowed")
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+
+	written := digits
+	country := home
+	national := digits
+	switch {
+	case strings.HasPrefix(s, "+") || strings.HasPrefix(digits, "00"):
+		digits = strings.TrimPrefix(digits, "00")
+		if country, ok = findCallingCode(digits); !ok {
+			// Without a numbering plan only the E.164 length can be checked
+			if len(digits) < 8 || len(digits) > 15 {
+				return "", "", fmt.Errorf("international numbers have 8 to 15 digits, got %d", len(digits))
+			}
+			display := "+" + strings.Join(group(digits, writtenGroups(s, len(written)-len(digits))), " ")
+			return "+" + digits + extLink(ext), display + extDisplay(ext), nil
+		}
+		national = digits[len(country.code):]
+		// A trunk prefix written in parentheses, as in +44 (0)20 ...
+		paren := strings.Contains(strings.ReplaceAll(s, " ", ""), "("+country.trunk+")")
+		if country.trunk != "" && (paren || len(national) > country.max) && strings.HasPrefix(national, country.trunk) {
+			national = national[len(country.trunk):]
+		}
+	case country.code == nanp && len(digits) == 11 && strings.HasPrefix(digits, nanp):
+		national = digits[1:]
+	case country.trunk != "" && strings.HasPrefix(digits, country.trunk):
+		national = digits[len(country.trunk):]
+	}
+
+	if len(national) < country.min || len(national) > country.max {
+		want := fmt.Sprint(country.min)
+		if country.max != country.min {
+			want = fmt.Sprintf("%d to %d", country.min, country.max)
+		}
+		return "", "", fmt.Errorf("%s numbers have %s digits after the country code, got %d", country.region, want, len(national))
+	}
+
+	e164 = "+" + country.code + national + extLink(ext)
+	groups := writtenGroups(s, len(written)-len(national))
+	if country.code == home.code {
+		return e164, country.nationalFormat(national, groups) + extDisplay(ext), nil
+	}
+	return e164, country.internationalFormat(national, groups) + extDisplay(ext), nil
+}
+
+func extLink(ext string) string {
+	if ext == "" {
+		return ""
+	}
+	return ";ext=" + ext
+}
+
+func extDisplay(ext string) string {
+	if ext == "" {
+		return ""
+	}
+	return " ext. " + ext
+}
+
+// writtenGroups returns the lengths of the runs of digits of a number as it
+// was written, without the first skip digits of the country code and trunk
+// prefix
+func writtenGroups(s string, skip int) []int {
+	var groups []int
+	n := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			n++
+			continue
+		}
+		if d := min(n, skip); n > 0 {
+			skip -= d
+			if n -= d; n > 0 {
+				groups = append(groups, n)
+			}
+		}
+		n = 0
+	}
+	return groups
+}
+
+// group splits the digits into groups of the given lengths
+func group(digits string, groups []int) []string {
+	var parts []string
+	for _, n := range groups {
+		if n >= len(digits) {
+			break
+		}
+		parts = append(parts, digits[:n])
+		digits = digits[n:]
+	}
+	return append(parts, digits)
+}
+
+// nanpGroups are the digits of a North American number, (123) 456-7890
+var nanpGroups = []int{3, 3}
+
+func (c phoneCountry) nationalFormat(national string, groups []int) string {
+	if c.code == nanp {
+		g := group(national, nanpGroups)
+		return fmt.Sprintf("(%s) %s", g[0], strings.Join(g[1:], "-"))
+	}
+	return c.trunk + strings.Join(group(national, groups), " ")
+}
+
+func (c phoneCountry) internationalFormat(national string, groups []int) string {
+	if c.code == nanp {
+		return "+" + c.code + " " + strings.Join(group(national, nanpGroups), "-")
+	}
+	return "+" + c.code + " " + strings.Join(group(national, groups), " ")
+}
+
+// parse sets the link and display forms of the number. An invalid number,
+// which validation reports, is kept as written.
+func (p *phone) parse(region, field string) {
+	p.e164, p.display = "", ""
+	if p.Number == "" {
+		return
+	}
+	e164, display, err := parsePhone(p.Number, region)
+	if err != nil {
+		log.Warnf("%s: %q is not a valid phone number: %v", field, p.Number, err)
+		return
+	}
+	p.e164, p.display = e164, display
+}
+
+// parsePhones parses every phone number of the resume
+func (r *resume) parsePhones(region string) {
+	r.Info.Phone.parse(region, "information.phone")
+	for i := range r.References {
+		r.References[i].Phone.parse(region, fmt.Sprintf("references[%d].phone", i))
+	}
+}
+
+func (p phone) String() string {
+	if p.display != "" {
+		return p.display
+	}
+	return p.Number
+}
+
+// tel returns the number for a tel: link, in E.164 form when it is valid
+func (p phone) tel() string {
+	if p.e164 != "" {
+		return p.e164
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune("0123456789+", r) {
+			return r
+		}
+		return -1
+	}, p.Number)
+}
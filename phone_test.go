@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+func TestParsePhone(t *testing.T) {
+	tests := []struct {
+		number, region string
+		e164, display  string
+	}{
+		// NANP
+		{"555.123.4567", "US", "+15551234567", "(555) 123-4567"},
+		{"1 (555) 123-4567", "US", "+15551234567", "(555) 123-4567"},
+		{"+1 555 123 4567", "CA", "+15551234567", "(555) 123-4567"},
+		{"5551234567 x89", "US", "+15551234567;ext=89", "(555) 123-4567 ext. 89"},
+		{"+1 555 123 4567", "GB", "+15551234567", "+1 555-123-4567"},
+		// UK
+		{"+44 20 7946 0958", "US", "+442079460958", "+44 20 7946 0958"},
+		{"+44 20 7946 0958", "GB", "+442079460958", "020 7946 0958"},
+		{"+44 (0)20 7946 0958", "US", "+442079460958", "+44 20 7946 0958"},
+		{"020 7946 0958", "GB", "+442079460958", "020 7946 0958"},
+		{"07700-900123", "GB", "+447700900123", "07700 900123"},
+		{"0044 7700 900123", "DE", "+447700900123", "+44 7700 900123"},
+		{"+442079460958", "US", "+442079460958", "+44 2079460958"},
+		// Germany
+		{"+49 30 123456", "US", "+4930123456", "+49 30 123456"},
+		{"030 123456", "DE", "+4930123456", "030 123456"},
+		{"+49 (0)30 1234 5678", "GB", "+493012345678", "+49 30 1234 5678"},
+		{"089/1234567", "DE", "+49891234567", "089 1234567"},
+		// Without a numbering plan
+		{"+380 44 123 4567", "US", "+380441234567", "+380 44 123 4567"},
+	}
+	for _, tt := range tests {
+		e164, display, err := parsePhone(tt.number, tt.region)
+		if err != nil {
+			t.Errorf("parsePhone(%q, %s) failed: %v", tt.number, tt.region, err)
+			continue
+		}
+		if e164 != tt.e164 || display != tt.display {
+			t.Errorf("parsePhone(%q, %s) = %q, %q, want %q, %q", tt.number, tt.region, e164, display, tt.e164, tt.display)
+		}
+	}
+}
+
+func TestParsePhoneInvalid(t *testing.T) {
+	tests := []struct {
+		number, region string
+	}{
+		{"555-1234", "US"},
+		{"+1 555 123 45678", "US"},
+		{"+44 20 7946", "US"},
+		{"+49 30 1", "US"},
+		{"call me", "US"},
+		{"+999 1", "US"},
+		{"555 123 4567", "XX"},
+	}
+	for _, tt := range tests {
+		if _, _, err := parsePhone(tt.number, tt.region); err == nil {
+			t.Errorf("parsePhone(%q, %s) did not fail", tt.number, tt.region)
+		}
+	}
+}
+
+func TestPhoneTel(t *testing.T) {
+	p := phone{Number: "+44 (0)20 7946 0958"}
+	p.parse("US", "phone")
+	if got := p.tel(); got != "+442079460958" {
+		t.Errorf("tel() = %q", got)
+	}
+	if got := p.String(); got != "+44 20 7946 0958" {
+		t.Errorf("String() = %q", got)
+	}
+	invalid := phone{Number: "call 555"}
+	invalid.parse("US", "phone")
+	if got := invalid.String(); got != "call 555" {
+		t.Errorf("an invalid number is shown as %q", got)
+	}
+}
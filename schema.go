@@ -0,0 +1,463 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	yaml "gopkg.in/yaml.v3"
+)
+
+//go:embed schemas/*.json
+var schemaFS embed.FS
+
+const (
+	configSchema      = "config.json"
+	resumeSchema      = "resume.json"
+	coverLetterSchema = "coverletter.json"
+)
+
+// schema is the subset of JSON Schema draft-07 used by the files in schemas/
+type schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Definitions          map[string]*schema `json:"definitions,omitempty"`
+	Type                 schemaType         `json:"type,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	OneOf                []*schema          `json:"oneOf,omitempty"`
+	AnyOf                []*schema          `json:"anyOf,omitempty"`
+	AllOf                []*schema          `json:"allOf,omitempty"`
+	Not                  *schema            `json:"not,omitempty"`
+
+	never bool     // the schema is `false`
+	order []string // order of Properties when generated from a struct
+}
+
+// schemaType is either a single type name or a list of them
+type schemaType []string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaType{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+func (s *schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = schema{never: !b}
+		return nil
+	}
+	type plain schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// schemaError is a single violation, located with the yaml.v3 node position
+type schemaError struct {
+	file   string
+	line   int
+	column int
+	field  string
+	msg    string
+}
+
+func (e schemaError) Error() string {
+	field := e.field
+	if field == "" {
+		field = "(root)"
+	}
+	if e.line == 0 {
+		return fmt.Sprintf("%s: %s: %s", e.file, field, e.msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.file, e.line, e.column, field, e.msg)
+}
+
+// schemaErrors reports every violation found in a document
+type schemaErrors []schemaError
+
+func (e schemaErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func loadSchema(name string) (*schema, error) {
+	data, err := schemaFS.ReadFile(path.Join("schemas", name))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading schema %s: %w", name, err)
+	}
+	var s schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("Error decoding schema %s: %w", name, err)
+	}
+	return &s, nil
+}
+
+// validator checks a YAML node tree against a schema
+type validator struct {
+	root   *schema
+	region string // of phone numbers without a country code
+	fileOf func(*yaml.Node) string
+	errs   schemaErrors
+}
+
+// validateNode validates the node against the named schema. fileOf names the
+// file each node was read from so merged documents are reported correctly.
+// Phone numbers without a country code are checked against the region.
+func validateNode(n *yaml.Node, schemaName, region string, fileOf func(*yaml.Node) string) (schemaErrors, error) {
+	s, err := loadSchema(schemaName)
+	if err != nil {
+		return nil, err
+	}
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	v := validator{root: s, region: region, fileOf: fileOf}
+	v.errs = v.check(s, n, "")
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		a, b := v.errs[i], v.errs[j]
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		return a.column < b.column
+	})
+	return v.errs, nil
+}
+
+// validateFile validates a single YAML file against the named schema
+func validateFile(file, schemaName string) (schemaErrors, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var n yaml.Node
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if n.Kind == 0 {
+		return nil, nil
+	}
+	return validateNode(&n, schemaName, "", func(*yaml.Node) string { return file })
+}
+
+func (v *validator) fail(n *yaml.Node, at, format string, args ...interface{}) schemaErrors {
+	return schemaErrors{{file: v.fileOf(n), line: n.Line, column: n.Column, field: at, msg: fmt.Sprintf(format, args...)}}
+}
+
+func (v *validator) resolve(s *schema) *schema {
+	for s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/definitions/")
+		def, ok := v.root.Definitions[name]
+		if !ok {
+			log.Warnf("Unresolved schema reference: %s", s.Ref)
+			return &schema{}
+		}
+		s = def
+	}
+	return s
+}
+
+func (v *validator) check(s *schema, n *yaml.Node, at string) schemaErrors {
+	s = v.resolve(s)
+	if s.never {
+		return v.fail(n, at, "is not allowed")
+	}
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+
+	var errs schemaErrors
+	if len(s.Type) > 0 && !matchesType(s.Type, n) {
+		return v.fail(n, at, "expected %s, got %s", strings.Join(s.Type, " or "), nodeType(n))
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		present := map[string]bool{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, val := n.Content[i], n.Content[i+1]
+			present[k.Value] = true
+			p := joinPath(at, k.Value)
+			if ps, ok := s.Properties[k.Value]; ok {
+				errs = append(errs, v.check(ps, val, p)...)
+			} else if s.AdditionalProperties != nil {
+				if v.resolve(s.AdditionalProperties).never {
+					errs = append(errs, v.fail(k, p, "unknown field")...)
+				} else {
+					errs = append(errs, v.check(s.AdditionalProperties, val, p)...)
+				}
+			}
+		}
+		for _, r := range s.Required {
+			if !present[r] {
+				errs = append(errs, v.fail(n, at, "missing required field %q", r)...)
+			}
+		}
+	case yaml.SequenceNode:
+		if s.MinItems != nil && len(n.Content) < *s.MinItems {
+			errs = append(errs, v.fail(n, at, "expected at least %d entries", *s.MinItems)...)
+		}
+		if s.Items != nil {
+			for i, item := range n.Content {
+				errs = append(errs, v.check(s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case yaml.ScalarNode:
+		errs = append(errs, v.checkScalar(s, n, at)...)
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == n.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, v.fail(n, at, "must be one of %v", s.Enum)...)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		errs = append(errs, v.check(sub, n, at)...)
+	}
+	if len(s.AnyOf) > 0 {
+		var (
+			matched bool
+			reasons []string
+		)
+		for _, sub := range s.AnyOf {
+			subErrs := v.check(sub, n, at)
+			if len(subErrs) == 0 {
+				matched = true
+				break
+			}
+			reasons = append(reasons, subErrs[0].msg)
+		}
+		if !matched {
+			errs = append(errs, v.fail(n, at, "does not match any of the allowed forms: %s", strings.Join(reasons, ", or "))...)
+		}
+	}
+	if len(s.OneOf) > 0 {
+		var matched int
+		var first schemaErrors
+		for _, sub := range s.OneOf {
+			subErrs := v.check(sub, n, at)
+			if len(subErrs) == 0 {
+				matched++
+			} else if first == nil {
+				first = subErrs
+			}
+		}
+		switch {
+		case matched == 0 && len(s.OneOf) == 1:
+			errs = append(errs, first...)
+		case matched == 0:
+			errs = append(errs, v.fail(n, at, "does not match any of the allowed forms")...)
+		case matched > 1:
+			errs = append(errs, v.fail(n, at, "matches more than one of the allowed forms")...)
+		}
+	}
+	if s.Not != nil && len(v.check(s.Not, n, at)) == 0 {
+		errs = append(errs, v.fail(n, at, "is not allowed")...)
+	}
+	return errs
+}
+
+func (v *validator) checkScalar(s *schema, n *yaml.Node, at string) schemaErrors {
+	var errs schemaErrors
+	val := n.Value
+	length := len([]rune(val))
+	if s.MinLength != nil && length < *s.MinLength {
+		errs = append(errs, v.fail(n, at, "must be at least %d characters", *s.MinLength)...)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		errs = append(errs, v.fail(n, at, "must be at most %d characters", *s.MaxLength)...)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			log.Warnf("Invalid schema pattern %q: %v", s.Pattern, err)
+		} else if !re.MatchString(val) {
+			errs = append(errs, v.fail(n, at, "%q does not match %s", val, s.Pattern)...)
+		}
+	}
+	if s.Minimum != nil || s.Maximum != nil {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			if s.Minimum != nil && f < *s.Minimum {
+				errs = append(errs, v.fail(n, at, "must be at least %v", *s.Minimum)...)
+			}
+			if s.Maximum != nil && f > *s.Maximum {
+				errs = append(errs, v.fail(n, at, "must be at most %v", *s.Maximum)...)
+			}
+		}
+	}
+	if s.Format == "phone" {
+		if _, _, err := parsePhone(val, v.region); err != nil {
+			errs = append(errs, v.fail(n, at, "%q is not a valid phone number: %v", val, err)...)
+		}
+	} else if s.Format != "" && !matchesFormat(s.Format, val) {
+		errs = append(errs, v.fail(n, at, "%q is not a valid %s", val, s.Format)...)
+	}
+	return errs
+}
+
+// nodeType names the JSON type of a node. yaml.v3 decodes any scalar into a
+// string field, so plain numbers and dates are also accepted where strings are.
+func nodeType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch n.ShortTag() {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	}
+	return "string"
+}
+
+func matchesType(types schemaType, n *yaml.Node) bool {
+	got := nodeType(n)
+	for _, t := range types {
+		switch {
+		case t == got:
+			return true
+		case t == "number" && got == "integer":
+			return true
+		case t == "string" && got != "object" && got != "array" && got != "null":
+			return true
+		}
+	}
+	return false
+}
+
+func matchesFormat(format, val string) bool {
+	switch format {
+	case "email":
+		_, err := mail.ParseAddress(val)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(val)
+		return err == nil && u.Scheme != ""
+	case "date":
+		_, err := time.Parse("2006-01-02", val)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, val)
+		return err == nil
+	}
+	return true
+}
+
+// validateResume validates the merged resume, and the cover letter when one will be generated
+func validateResume(doc *resumeDoc, cover bool, region string) error {
+	fileOf := func(n *yaml.Node) string { return doc.origin[n] }
+	errs, err := validateNode(doc.root, resumeSchema, region, fileOf)
+	if err != nil {
+		return err
+	}
+	if cover {
+		cvr, err := validateNode(doc.root, coverLetterSchema, region, fileOf)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, cvr...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateCommand validates the configuration file and the resume files against the shipped schemas
+func validateCommand(p config, args []string) error {
+	var errs schemaErrors
+	configFile := ".config"
+	if _, err := os.Stat(configFile); err == nil {
+		cfgErrs, err := validateFile(configFile, configSchema)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, cfgErrs...)
+		var c config
+		if err := readConfigFile(configFile, &c); err == nil {
+			overwriteStruct(&c, &p)
+			p = c
+		}
+	}
+
+	if len(args) == 0 {
+		if p.BaseFile == "" {
+			return fmt.Errorf("usage: validate [-b base.yml] <resume.yml>...")
+		}
+		// Validate the base resume on its own
+		args = []string{""}
+	}
+	for _, file := range args {
+		doc, err := loadResumeDoc(p.BaseFile, file)
+		if err != nil {
+			return err
+		}
+		if err := validateResume(doc, p.Cover, p.Region); err != nil {
+			resErrs, ok := err.(schemaErrors)
+			if !ok {
+				return err
+			}
+			errs = append(errs, resErrs...)
+		}
+	}
+
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Println(e)
+		}
+		return fmt.Errorf("%d schema violations found", len(errs))
+	}
+	log.Infof("No schema violations found")
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testValidResume = `information:
+  name: Jane Doe
+  email: jane@example.com
+  phone: (555) 123-4567
+education:
+  - name: State University
+    major: Computer Science
+    location: Springfield
+    start_date: 2014-09
+    end_date:
+      expected: 2026-05
+experience:
+  - company: Acme
+    title: Engineer
+    location: Remote
+    start_date: Summer 2020
+    end_date: Present
+    description:
+      - Built the API
+      - text: Tuned the runtime
+        tags: [go]
+  - company: Globex
+    roles:
+      - title: Lead
+        start_date: 2018
+        end_date: 2020-05
+`
+
+func validateTestDoc(t *testing.T, schemaName, doc string) schemaErrors {
+	t.Helper()
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &n); err != nil {
+		t.Fatal(err)
+	}
+	errs, err := validateNode(&n, schemaName, "US", func(*yaml.Node) string { return "test.yml" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return errs
+}
+
+func TestValidateResumeValid(t *testing.T) {
+	if errs := validateTestDoc(t, resumeSchema, testValidResume); len(errs) > 0 {
+		t.Errorf("a valid resume failed validation:\n%v", errs)
+	}
+}
+
+func TestValidateResumeErrors(t *testing.T) {
+	tests := []struct {
+		name, old, new, want string
+	}{
+		{"missing required", "  email: jane@example.com\n", "", `test.yml:2:3: information: missing required field "email"`},
+		{"invalid email", "jane@example.com", "jane.example.com", `test.yml:3:10: information.email: "jane.example.com" is not a valid email`},
+		{"invalid phone", "(555) 123-4567", "555-1234", `information.phone: "555-1234" is not a valid phone number`},
+		{"wrong type", "    location: Remote\n", "    location: [Remote]\n", "test.yml:15:15: experience[0].location: expected string, got array"},
+		{"bad date mapping", "      expected: 2026-05\n", "      maybe: 2026-05\n", "education[0].end_date: does not match any of the allowed forms"},
+		{"experience without title or roles", "  - company: Globex\n    roles:\n      - title: Lead\n", "  - company: Globex\n    foo:\n      - title: Lead\n", `test.yml:22:5: experience[1]: does not match any of the allowed forms: missing required field "title", or missing required field "roles"`},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(testValidResume, tt.old) {
+			t.Fatalf("%s: %q is not in the test resume", tt.name, tt.old)
+		}
+		errs := validateTestDoc(t, resumeSchema, strings.Replace(testValidResume, tt.old, tt.new, 1))
+		if !strings.Contains(errs.Error(), tt.want) {
+			t.Errorf("%s: errors = %v, want %q", tt.name, errs, tt.want)
+		}
+	}
+}
+
+func TestValidateTheme(t *testing.T) {
+	errs := validateTestDoc(t, resumeSchema, testValidResume+"theme:\n  font_size: 9pt\n  accent_color: blue\n  margins:\n    top: 1px\n")
+	for _, want := range []string{"theme.font_size: must be one of", "theme.accent_color:", "theme.margins.top:"} {
+		if !strings.Contains(errs.Error(), want) {
+			t.Errorf("errors lack %q:\n%v", want, errs)
+		}
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	errs := validateTestDoc(t, configSchema, "engine: lualatex\nengin: xelatex\n")
+	if want := "test.yml:2:1: engin: unknown field"; errs.Error() != want {
+		t.Errorf("errors = %v, want %q", errs, want)
+	}
+}
+
+func TestSchemasUpToDate(t *testing.T) {
+	g, err := newSchemaGen(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := generateSchemas(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range generated {
+		current, err := schemaFS.ReadFile("schemas/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(current, want) {
+			t.Errorf("schemas/%s is out of date, run `go run . schemas`", name)
+		}
+	}
+}
+
+func TestSchemaGenStruct(t *testing.T) {
+	type sample struct {
+		Name    string   `yaml:"name"`
+		Level   string   `yaml:"level,omitempty" schema:"enum=low|high"`
+		Site    string   `yaml:"site,omitempty" schema:"format=uri"`
+		Tags    []string `yaml:"tags,omitempty"`
+		Count   int
+		Skipped string `yaml:"-"`
+		Hidden  string `schema:"-"`
+		private string
+	}
+	g := &schemaGen{comments: map[string]map[string]string{"sample": {
+		"Name":  "Name of the sample (Required) Example: Jane",
+		"Level": "Level of the sample (Optional)",
+	}}}
+	out, err := json.Marshal(g.forType(reflect.TypeOf(sample{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"object","required":["name"],"properties":{` +
+		`"name":{"description":"Name of the sample\nExample: Jane","type":"string"},` +
+		`"level":{"description":"Level of the sample","type":"string","enum":["low","high"]},` +
+		`"site":{"type":"string","format":"uri"},` +
+		`"tags":{"type":"array","items":{"type":"string"}},` +
+		`"count":{"type":"integer"}}}`
+	if string(out) != want {
+		t.Errorf("schema =\n%s\nwant\n%s", out, want)
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+const draft07 = "http://json-schema.org/draft-07/schema#"
+
+// schemaProvider is implemented by types that decode from YAML in their own
+// way and therefore do not look like their Go struct
+type schemaProvider interface {
+	jsonSchema(g *schemaGen) *schema
+}
+
+func (t schemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (s *schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	type plain schema
+	p := plain(*s)
+	props := p.Properties
+	p.Properties = nil
+	out, err := json.Marshal(p)
+	if err != nil || len(props) == 0 {
+		return out, err
+	}
+
+	// Properties are written last and in the order of the struct they came from
+	keys := append([]string{}, s.order...)
+	for k := range props {
+		if !contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	var buf bytes.Buffer
+	buf.Write(out[:len(out)-1])
+	if len(out) > 2 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"properties":{`)
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		val, err := json.Marshal(props[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaGen builds JSON schemas from the Go structs, using the yaml tags for
+// property names and the inline field comments as descriptions
+type schemaGen struct {
+	comments map[string]map[string]string // type name -> field name -> comment
+}
+
+var commentRe = regexp.MustCompile(`^(.*?)\s*(?:\((Required|Optional)\))?\s*(?:Example:\s*(.*))?$`)
+
+// newSchemaGen reads the field comments from the Go source files in dir
+func newSchemaGen(dir string) (*schemaGen, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go source files found in %s, run this from the source directory", dir)
+	}
+	g := &schemaGen{comments: map[string]map[string]string{}}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			fields := map[string]string{}
+			for _, field := range st.Fields.List {
+				if field.Comment == nil {
+					continue
+				}
+				for _, name := range field.Names {
+					fields[name.Name] = strings.TrimSpace(field.Comment.Text())
+				}
+			}
+			g.comments[spec.Name.Name] = fields
+			return false
+		})
+	}
+	return g, nil
+}
+
+// describe returns the description of a field and whether it is required
+func (g *schemaGen) describe(parent reflect.Type, f reflect.StructField) (string, bool) {
+	if comment, ok := g.comments[parent.Name()][f.Name]; ok {
+		m := commentRe.FindStringSubmatch(comment)
+		desc := m[1]
+		if m[3] != "" {
+			desc += "\nExample: " + m[3]
+		}
+		return desc, m[2] == "Required"
+	}
+	if tag := f.Tag.Get("form"); tag != "" {
+		parts := strings.Split(tag, ";")
+		for i, p := range parts {
+			parts[i] = strings.TrimSpace(p)
+		}
+		title, desc, _, _ := parseTagOptions(parts[1:], f.Name)
+		if desc == "" {
+			desc = title
+		}
+		// The schema cannot know the registry of the user, so it lists the built-in sections
+		desc = strings.ReplaceAll(desc, "{sections}", sectionRegistry(defaultSections).describe(", "))
+		return desc, false
+	}
+	return "", false
+}
+
+func (g *schemaGen) forType(t reflect.Type) *schema {
+	if p, ok := reflect.Zero(t).Interface().(schemaProvider); ok {
+		return p.jsonSchema(g)
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &schema{Type: schemaType{"string"}}
+	case reflect.Bool:
+		return &schema{Type: schemaType{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: schemaType{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: schemaType{"number"}}
+	case reflect.Slice, reflect.Array:
+		return &schema{Type: schemaType{"array"}, Items: g.forType(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: schemaType{"object"}, AdditionalProperties: g.forType(t.Elem())}
+	case reflect.Ptr:
+		return g.forType(t.Elem())
+	case reflect.Struct:
+		return g.forStruct(t)
+	}
+	return &schema{}
+}
+
+func (g *schemaGen) forStruct(t reflect.Type) *schema {
+	s := &schema{Type: schemaType{"object"}, Properties: map[string]*schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Tag.Get("schema") == "-" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fs := g.forType(f.Type)
+		desc, required := g.describe(t, f)
+		if desc != "" {
+			fs.Description = desc
+		}
+		for _, opt := range strings.Split(f.Tag.Get("schema"), ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch k {
+			case "format":
+				fs.Format = v
+			case "pattern":
+				fs.Pattern = v
+			case "enum":
+				for _, e := range strings.Split(v, "|") {
+					fs.Enum = append(fs.Enum, e)
+				}
+			}
+		}
+		s.Properties[name] = fs
+		s.order = append(s.order, name)
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// generateSchemas returns the content of every file in schemas/ by file name
+func generateSchemas(g *schemaGen) (map[string][]byte, error) {
+	cfg := g.forType(reflect.TypeOf(config{}))
+	cfg.Schema, cfg.Title, cfg.Description = draft07, "Configuration", "Configuration options for the application"
+	cfg.AdditionalProperties = &schema{never: true}
+
+	res := g.forType(reflect.TypeOf(resume{}))
+	res.Schema, res.Title = draft07, "Resume and Cover Letter Schema"
+
+	cvr := &schema{
+		Schema:     draft07,
+		Title:      "Cover Letter Schema",
+		Type:       schemaType{"object"},
+		Properties: map[string]*schema{"cover_letter": res.Properties["cover_letter"]},
+	}
+
+	out := map[string][]byte{}
+	for name, s := range map[string]*schema{configSchema: cfg, resumeSchema: res, coverLetterSchema: cvr} {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(s); err != nil {
+			return nil, fmt.Errorf("Error encoding schema %s: %w", name, err)
+		}
+		out[name] = buf.Bytes()
+	}
+	return out, nil
+}
+
+// schemasCommand regenerates the files in schemas/ from the Go structs.
+// With check it only reports the schemas that are out of date.
+func schemasCommand(_ config, args []string) error {
+	check := len(args) > 0 && args[0] == "check"
+	if len(args) > 1 || (len(args) == 1 && !check) {
+		return fmt.Errorf("usage: schemas [check]")
+	}
+	g, err := newSchemaGen(".")
+	if err != nil {
+		return err
+	}
+	generated, err := generateSchemas(g)
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	for _, name := range []string{configSchema, resumeSchema, coverLetterSchema} {
+		file := path.Join("schemas", name)
+		if check {
+			current, err := os.ReadFile(file)
+			if err != nil || !bytes.Equal(current, generated[name]) {
+				stale = append(stale, file)
+			}
+			continue
+		}
+		if err := os.WriteFile(file, generated[name], 0644); err != nil {
+			return fmt.Errorf("Error writing schema: %w", err)
+		}
+		log.Infof("Generated %s", file)
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("schemas are out of date, run `go run . schemas` to regenerate: %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
+
+// A date is written as text, or as expected: 2026-05 when it has not happened yet
+func (date) jsonSchema(*schemaGen) *schema {
+	expected := &schema{
+		Type:                 schemaType{"object"},
+		Properties:           map[string]*schema{"expected": {Type: schemaType{"string"}, Description: "Date that has not happened yet"}},
+		Required:             []string{"expected"},
+		AdditionalProperties: &schema{never: true},
+		order:                []string{"expected"},
+	}
+	return &schema{OneOf: []*schema{{Type: schemaType{"string"}}, expected}}
+}
+
+func (phone) jsonSchema(*schemaGen) *schema {
+	return &schema{Type: schemaType{"string"}, Format: "phone"}
+}
+
+func (bullet) jsonSchema(g *schemaGen) *schema {
+	return &schema{OneOf: []*schema{{Type: schemaType{"string"}}, g.forStruct(reflect.TypeOf(bullet{}))}}
+}
+
+func (customSections) jsonSchema(g *schemaGen) *schema {
+	c := g.forStruct(reflect.TypeOf(custom{}))
+	return &schema{OneOf: []*schema{{Type: schemaType{"array"}, Items: c}, c}}
+}
+
+// An experience either describes a single role itself or lists its roles
+func (experience) jsonSchema(g *schemaGen) *schema {
+	s := g.forStruct(reflect.TypeOf(experience{}))
+	single := &schema{Required: s.Required}
+	s.Required = []string{"company"}
+	s.AnyOf = []*schema{single, {Required: []string{"roles"}}}
+	return s
+}
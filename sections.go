@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// section is a part of the resume placed on the page by its code in Order
+type section struct {
+	Code     string `yaml:"code" schema:"pattern=^[a-z0-9]$"` // Letter that places the section in the order (Required) Example: x
+	Name     string `yaml:"name"`                             // Name of the section, used by template packs and in the TeX section markers. Required for new sections (Optional) Example: Experience
+	Template string `yaml:"template,omitempty"`               // Template that renders the section, the name when empty. HTML uses it with the html_ prefix (Optional) Example: Experience
+	Title    string `yaml:"title,omitempty"`                  // Heading shown by the templates through the title function (Optional) Example: Relevant Experience
+	custom   string // key of the custom section rendered, empty for t
+}
+
+// defaultSections are the sections of the built-in templates. Custom and
+// Summary take their heading from the resume.
+var defaultSections = []section{
+	{Code: "e", Name: "Education", Title: "Education"},
+	{Code: "x", Name: "Experience", Title: "Relevant Experience"},
+	{Code: "p", Name: "Projects", Title: "Projects"},
+	{Code: "s", Name: "Skills", Title: "Skills"},
+	{Code: "c", Name: "Certifications", Title: "Certifications"},
+	{Code: "u", Name: "Publications", Title: "Publications"},
+	{Code: "a", Name: "Awards", Title: "Awards"},
+	{Code: "v", Name: "Volunteer", Title: "Volunteer Experience"},
+	{Code: "l", Name: "Languages", Title: "Languages"},
+	{Code: "i", Name: "Interests", Title: "Interests"},
+	{Code: "r", Name: "References", Title: "References"},
+	{Code: "t", Name: "Custom"},
+	{Code: "m", Name: "Summary"},
+}
+
+// template returns the name of the template rendering the section
+func (s section) template() string {
+	if s.Template != "" {
+		return s.Template
+	}
+	return s.Name
+}
+
+type sectionRegistry []section
+
+// registry returns the built-in sections, changed or extended by the
+// configured ones. A configured section replaces the fields it sets of the
+// built-in section with the same code.
+func (c config) registry() (sectionRegistry, error) {
+	reg := append(sectionRegistry{}, defaultSections...)
+	for _, s := range c.Sections {
+		s.Code = strings.ToLower(strings.TrimSpace(s.Code))
+		if len([]rune(s.Code)) != 1 {
+			return nil, fmt.Errorf("section %q: code must be a single letter, got %q", s.Name, s.Code)
+		}
+		i := reg.index(s.Code)
+		if i < 0 {
+			if s.Name == "" {
+				return nil, fmt.Errorf("section with code %q has no name", s.Code)
+			}
+			reg = append(reg, s)
+			continue
+		}
+		if err := overwriteStruct(&reg[i], &s); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+func (reg sectionRegistry) index(code string) int {
+	for i, s := range reg {
+		if s.Code == code {
+			return i
+		}
+	}
+	return -1
+}
+
+// withCustom returns the registry with a section for every custom section
+// of the resume that has a key. The others are rendered by t.
+func (reg sectionRegistry) withCustom(customs customSections) (sectionRegistry, error) {
+	out := append(sectionRegistry{}, reg...)
+	for _, c := range customs {
+		if c.Key == "" {
+			continue
+		}
+		if out.find(c.Key) >= 0 {
+			return nil, fmt.Errorf("custom section key %q is already used by another section", c.Key)
+		}
+		out = append(out, section{Name: c.Key, Template: "Custom", custom: c.Key})
+	}
+	return out, nil
+}
+
+// find returns the index of the section with the code or name, -1 if none
+func (reg sectionRegistry) find(key string) int {
+	if len([]rune(key)) == 1 {
+		return reg.index(key)
+	}
+	for i, s := range reg {
+		if strings.EqualFold(s.Name, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// codes returns every section code, in registry order
+func (reg sectionRegistry) codes() string {
+	var b strings.Builder
+	for _, s := range reg {
+		b.WriteString(s.Code)
+	}
+	return b.String()
+}
+
+// describe lists the codes and names, e.g. [x]Experience. Sections without
+// a code are listed by name.
+func (reg sectionRegistry) describe(sep string) string {
+	var desc []string
+	for _, s := range reg {
+		if s.Code == "" {
+			desc = append(desc, s.Name)
+			continue
+		}
+		desc = append(desc, fmt.Sprintf("[%s]%s", s.Code, s.Name))
+	}
+	return strings.Join(desc, sep)
+}
+
+// parseOrder returns the sections of an order such as "xsep". An order with
+// commas lists codes or names, e.g. "x,s,publications,e".
+func (reg sectionRegistry) parseOrder(order string) ([]section, error) {
+	var keys []string
+	if strings.Contains(order, ",") {
+		for _, key := range strings.Split(order, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	} else {
+		for _, code := range order {
+			keys = append(keys, string(code))
+		}
+	}
+	var out []section
+	for _, key := range keys {
+		i := reg.find(strings.ToLower(key))
+		if i < 0 {
+			return nil, fmt.Errorf("unknown section %q in order %q, valid sections are: %s", key, order, reg.describe(", "))
+		}
+		out = append(out, reg[i])
+	}
+	return out, nil
+}
+
+// customs returns the custom sections rendered by s: the one with its key,
+// or every custom section that the order does not place by key
+func (s section) customs(all customSections, order []section) customSections {
+	var out customSections
+	for _, c := range all {
+		if s.custom != "" && c.Key == s.custom {
+			out = append(out, c)
+		}
+		if s.custom == "" && (c.Key == "" || !placed(order, c.Key)) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func placed(order []section, key string) bool {
+	for _, s := range order {
+		if s.custom == key {
+			return true
+		}
+	}
+	return false
+}
+
+// title returns the heading of the named section, the name when none is set
+func (reg sectionRegistry) title(name string) string {
+	for _, s := range reg {
+		if strings.EqualFold(s.Name, name) && s.Title != "" {
+			return s.Title
+		}
+	}
+	return name
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCustomSectionKeys(t *testing.T) {
+	r := resume{
+		Info: info{Name: "Jane Doe"},
+		Custom: customSections{
+			{Key: "side_projects", Title: "Side Projects", Body: []string{"Built a compiler"}},
+			{Title: "Hobbies", Body: []string{"Chess"}},
+		},
+	}
+	reg, err := sectionRegistry(defaultSections).withCustom(r.Custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := reg.parseOrder("x,side_projects,t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tex := renderTest(t, &r, order, "resume")
+	_, keyed, _ := strings.Cut(tex, sectionMarker+"side_projects\n")
+	keyed, rest, _ := strings.Cut(keyed, sectionMarker+"Custom\n")
+	if !strings.Contains(keyed, "Side Projects") || !strings.Contains(keyed, "Built a compiler") || strings.Contains(keyed, "Hobbies") {
+		t.Errorf("the side_projects section renders:\n%s", keyed)
+	}
+	if !strings.Contains(rest, "Hobbies") || strings.Contains(rest, "Side Projects") {
+		t.Errorf("the custom section renders:\n%s", rest)
+	}
+	if r.Custom[0].Key != "side_projects" {
+		t.Errorf("rendering changed the key to %q", r.Custom[0].Key)
+	}
+}
+
+func TestEmptySectionsSkipped(t *testing.T) {
+	// A resume with only the sections that existed before the new ones
+	r := resume{
+		Info:           info{Name: "Jane Doe", Email: "jane@example.com"},
+		Education:      []school{{Name: "State University"}},
+		Experiences:    []experience{{Company: "Acme", Title: "Engineer"}},
+		Skills:         []skill{{Name: "Languages", Keywords: []string{"Go"}}},
+		Certifications: []certification{{Name: "CKA"}},
+		Summary:        summary{Title: "Summary", Body: "Builds things."},
+	}
+	reg := sectionRegistry(defaultSections)
+	all, err := reg.parseOrder(reg.codes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := reg.parseOrder("expsctm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Section markers are written for every section of the order
+	unmarked := func(s string) string {
+		var lines []string
+		for _, l := range strings.Split(s, "\n") {
+			if !strings.HasPrefix(l, sectionMarker) {
+				lines = append(lines, l)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	for _, tmplType := range []string{"resume", "html"} {
+		if got, want := unmarked(renderTest(t, &r, all, tmplType)), unmarked(renderTest(t, &r, old, tmplType)); got != want {
+			t.Errorf("%s: the full order renders empty sections:\n%s", tmplType, got)
+		}
+	}
+}
+
+func TestConfigRegistry(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections []section
+		order    string
+		want     string // name:template:title of each section of the order
+		wantErr  string
+	}{
+		{"defaults", nil, "xe", "Experience:Experience:Relevant Experience,Education:Education:Education", ""},
+		{"names", nil, "experience, Summary", "Experience:Experience:Relevant Experience,Summary:Summary:", ""},
+		{"retitled", []section{{Code: "X", Title: "Work"}}, "x", "Experience:Experience:Work", ""},
+		{"added", []section{{Code: "o", Name: "Talks", Template: "Custom", Title: "Talks"}}, "ox", "Talks:Custom:Talks,Experience:Experience:Relevant Experience", ""},
+		{"long code", []section{{Code: "xx", Name: "Work"}}, "x", "", `section "Work": code must be a single letter, got "xx"`},
+		{"no name", []section{{Code: "o"}}, "o", "", `section with code "o" has no name`},
+		{"unknown code", nil, "xz", "", `unknown section "z" in order "xz", valid sections are: [e]Education, [x]Experience,`},
+		{"unknown name", nil, "x,talks", "", `unknown section "talks" in order "x,talks"`},
+	}
+	for _, tt := range tests {
+		reg, err := config{Sections: tt.sections}.registry()
+		var order []section
+		if err == nil {
+			order, err = reg.parseOrder(tt.order)
+		}
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, s := range order {
+			got = append(got, s.Name+":"+s.template()+":"+s.Title)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%s: order = %s, want %s", tt.name, strings.Join(got, ","), tt.want)
+		}
+	}
+}
+
+func TestRegistryDescribe(t *testing.T) {
+	reg, err := config{Sections: []section{{Code: "o", Name: "Talks"}}}.registry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, err = reg.withCustom(customSections{{Key: "hobbies"}, {Title: "Unkeyed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reg.codes(), "expscuavlirtmo"; got != want {
+		t.Errorf("codes = %q, want %q", got, want)
+	}
+	if got := reg.describe(" "); !strings.HasSuffix(got, "[m]Summary [o]Talks hobbies") {
+		t.Errorf("describe = %q", got)
+	}
+	if got := reg.title("experience"); got != "Relevant Experience" {
+		t.Errorf("title = %q", got)
+	}
+	if _, err := reg.withCustom(customSections{{Key: "talks"}}); err == nil {
+		t.Error("a custom section key taken by a section was accepted")
+	}
+}
+
+func TestCustomSectionsYAML(t *testing.T) {
+	tests := []struct {
+		name, doc string
+		want      customSections
+	}{
+		{"single mapping", "custom:\n  title: Hobbies\n  body: [Chess]\n", customSections{{Title: "Hobbies", Body: []string{"Chess"}}}},
+		{"list", "custom:\n  - key: talks\n    title: Talks\n  - title: Hobbies\n", customSections{{Key: "talks", Title: "Talks"}, {Title: "Hobbies"}}},
+	}
+	for _, tt := range tests {
+		var r resume
+		if err := yaml.Unmarshal([]byte(tt.doc), &r); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(r.Custom, tt.want) {
+			t.Errorf("%s: custom = %+v, want %+v", tt.name, r.Custom, tt.want)
+		}
+	}
+}
+
+func TestCustomSectionEntries(t *testing.T) {
+	r := resume{
+		Info: info{Name: "Jane Doe"},
+		Custom: customSections{{
+			Key:         "talks",
+			Title:       "Talks",
+			Description: "Selected talks",
+			Entries: []customEntry{
+				{Name: "GopherCon", Subtitle: "Speaker", Location: "Denver, CO", URL: "https://gophercon.com", Date: date{text: "2023"}, Description: []string{"Spoke about **generics**"}},
+				{Name: "Meetup"},
+			},
+		}},
+	}
+	reg, err := sectionRegistry(defaultSections).withCustom(r.Custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := reg.parseOrder("m,talks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tmplType string
+		want     []string
+	}{
+		{"resume", []string{`\section{Talks}`, "Selected talks", `\href{https://gophercon.com}{\textbf{GopherCon}} \hfill \textbf{2023}`, `\textit{Speaker} \hfill \textit{ \small Denver, CO}`, `\textbf{generics}`, `\textbf{Meetup}`}},
+		{"html", []string{"<h2>Talks</h2>", `<a class="title" href="https://gophercon.com">GopherCon</a><span class="right title">2023</span>`, `<span class="sub">Speaker</span>`, "<strong>generics</strong>", `<span class="title">Meetup</span>`}},
+	}
+	for _, tt := range tests {
+		out := renderTest(t, &r, order, tt.tmplType)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output lacks %q:\n%s", tt.tmplType, want, out)
+			}
+		}
+	}
+}
+
+const testNewSections = `information:
+  name: Jane Doe
+publications:
+  - title: Scaling Builds
+    authors: [J. Doe]
+    venue: GopherCon
+    doi: 10.1145/3368089.3409740
+awards:
+  - title: Dean's List
+    awarder: State University
+volunteer:
+  - organization: Red Cross
+    position: Driver
+    description: [Delivered supplies]
+languages:
+  - name: Spanish
+    level: C1
+  - name: French
+interests:
+  - name: Music
+    keywords: [Piano]
+references:
+  - name: John Smith
+    position: Manager
+    reference: A pleasure to work with
+`
+
+func TestNewSections(t *testing.T) {
+	var r resume
+	if err := yaml.Unmarshal([]byte(testNewSections), &r); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		code  string
+		latex []string
+		html  []string
+	}{
+		{"u", []string{`\section{Publications}`, `\textbf{Scaling Builds}`, `\textit{GopherCon}`, `doi:\href{https://doi.org/10.1145/3368089.3409740}`}, []string{"<h2>Publications</h2>", "Scaling Builds", "GopherCon", `href="https://doi.org/10.1145/3368089.3409740"`}},
+		{"a", []string{`\section{Awards}`, `\textbf{Dean's List} | \textit{State University}`}, []string{"<h2>Awards</h2>", "Dean&#39;s List", "State University"}},
+		{"v", []string{`\section{Volunteer Experience}`, `\textbf{Red Cross}`, `\textit{Driver}`, `\item {Delivered supplies}`}, []string{"<h2>Volunteer Experience</h2>", "Red Cross", "Driver", "Delivered supplies"}},
+		{"l", []string{`\section{Languages}`, `\textbf{Spanish} \textit{C1} \textbar{} \textbf{French}`}, []string{"<h2>Languages</h2>", "Spanish", "C1", "French"}},
+		{"i", []string{`\section{Interests}`, `\textbf{Music:}`, "Piano"}, []string{"<h2>Interests</h2>", "Music", "Piano"}},
+		{"r", []string{`\section{References}`, `\textbf{John Smith} | \textit{Manager}`, "``A pleasure to work with''"}, []string{"<h2>References</h2>", "John Smith", "Manager", "A pleasure to work with"}},
+	}
+	reg := sectionRegistry(defaultSections)
+	for _, tt := range tests {
+		order, err := reg.parseOrder(tt.code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for tmplType, wants := range map[string][]string{"resume": tt.latex, "html": tt.html} {
+			out := renderTest(t, &r, order, tmplType)
+			for _, want := range wants {
+				if !strings.Contains(out, want) {
+					t.Errorf("%s: %s output lacks %q:\n%s", tt.code, tmplType, want, out)
+				}
+			}
+		}
+	}
+}
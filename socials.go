@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// platform is how the profile of a social platform is linked and shown. The
+// patterns replace {username} with the username, and {user} and {host} with
+// the parts of a user@host username such as a Mastodon handle.
+type platform struct {
+	Name     string   `yaml:"name"`                // Name of the Platform, matched ignoring case, spaces and dashes (Required) Example: Codeberg
+	Aliases  []string `yaml:"aliases,omitempty"`   // Other names of the Platform (Optional) Example: [cb]
+	URL      string   `yaml:"url,omitempty"`       // URL pattern of a profile. Required for new platforms (Optional) Example: https://codeberg.org/{username}
+	Display  string   `yaml:"display,omitempty"`   // Text shown for a profile, the URL without its scheme when empty (Optional) Example: codeberg.org/{username}
+	Icon     string   `yaml:"icon,omitempty"`      // FontAwesome 5 command of the icon in the PDF (Optional) Example: \faGit
+	HTMLIcon string   `yaml:"html_icon,omitempty"` // HTML of the icon in the web page, such as an emoji or an <img> (Optional) Example: <img src="codeberg.svg" alt="">
+}
+
+var defaultPlatforms = []platform{
+	{Name: "GitHub", URL: "https://www.github.com/{username}", Display: "github.com/{username}", Icon: `\faGithub`},
+	{Name: "LinkedIn", URL: "https://www.linkedin.com/in/{username}", Display: "linkedin.com/in/{username}", Icon: `\faLinkedin`},
+	{Name: "GitLab", URL: "https://gitlab.com/{username}", Display: "gitlab.com/{username}", Icon: `\faGitlab`},
+	{Name: "Stack Overflow", Aliases: []string{"so"}, URL: "https://stackoverflow.com/users/{username}", Display: "stackoverflow.com/users/{username}", Icon: `\faStackOverflow`},
+	{Name: "Website", Aliases: []string{"web", "homepage", "portfolio"}, URL: "https://{username}", Display: "{username}", Icon: `\faGlobe`},
+	{Name: "Mastodon", URL: "https://{host}/@{user}", Display: "@{user}@{host}", Icon: `\faMastodon`},
+	{Name: "ORCID", URL: "https://orcid.org/{username}", Display: "orcid.org/{username}", Icon: `\faOrcid`},
+	{Name: "Google Scholar", Aliases: []string{"scholar"}, URL: "https://scholar.google.com/citations?user={username}", Display: "Google Scholar", Icon: `\faGraduationCap`},
+	{Name: "X", Aliases: []string{"twitter"}, URL: "https://x.com/{username}", Display: "@{username}", Icon: `\faTwitter`},
+}
+
+type platformRegistry []platform
+
+// platformKey normalizes a platform name, e.g. "Stack Overflow" to "stackoverflow"
+func platformKey(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "", ".", "").Replace(name))
+}
+
+// platforms returns the built-in platforms, changed or extended by the
+// configured ones. A configured platform replaces the fields it sets of the
+// built-in platform with the same name.
+func (c config) platforms() (platformRegistry, error) {
+	reg := append(platformRegistry{}, defaultPlatforms...)
+	for _, p := range c.Platforms {
+		if p.Name == "" {
+			return nil, fmt.Errorf("social platform with url %q has no name", p.URL)
+		}
+		existing := reg.find(p.Name)
+		if existing == nil {
+			if p.URL == "" {
+				return nil, fmt.Errorf("social platform %s has no url", p.Name)
+			}
+			reg = append(reg, p)
+			continue
+		}
+		if err := overwriteStruct(existing, &p); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// find returns the platform with the name or alias, nil if none
+func (reg platformRegistry) find(name string) *platform {
+	key := platformKey(name)
+	for i, p := range reg {
+		if platformKey(p.Name) == key {
+			return &reg[i]
+		}
+		for _, a := range p.Aliases {
+			if platformKey(a) == key {
+				return &reg[i]
+			}
+		}
+	}
+	return nil
+}
+
+// expand fills the placeholders of a pattern with the username
+func expand(pattern, username string) string {
+	user, host, _ := strings.Cut(strings.TrimPrefix(username, "@"), "@")
+	return strings.NewReplacer("{username}", username, "{user}", user, "{host}", host).Replace(pattern)
+}
+
+// link sets the URL, text and icons of the profile. A url set on the profile
+// takes precedence over the one of the platform.
+func (s *social) link(reg platformRegistry) {
+	p := reg.find(s.Platform)
+	if p == nil {
+		p = &platform{Name: s.Platform}
+		if s.URL == "" {
+			log.Warnf("Unknown social platform %q, set the url of the profile or add the platform to the configuration", s.Platform)
+		}
+	}
+	s.url = s.URL
+	if s.url == "" && p.URL != "" && s.Username != "" {
+		s.url = expand(p.URL, s.Username)
+	}
+	s.icon, s.htmlIcon = p.Icon, p.HTMLIcon
+	switch {
+	case p.Display != "" && s.Username != "":
+		s.display = expand(p.Display, s.Username)
+	case s.url != "":
+		d := s.url
+		if i := strings.Index(d, "://"); i >= 0 {
+			d = d[i+3:]
+		}
+		s.display = strings.TrimPrefix(strings.TrimSuffix(d, "/"), "www.")
+	default:
+		s.display = fmt.Sprintf("%s@%s", s.Username, s.Platform)
+	}
+}
+
+// linkSocials resolves the links of every profile of the resume
+func (r *resume) linkSocials(c config) error {
+	reg, err := c.platforms()
+	if err != nil {
+		return err
+	}
+	for i := range r.Info.Socials {
+		r.Info.Socials[i].link(reg)
+	}
+	return nil
+}
+
+func (s social) getURL() string {
+	return s.url
+}
+
+func (s social) getIcon() string {
+	return s.icon
+}
+
+// getHTMLIcon returns the icon of the web page, configured HTML that is trusted
+func (s social) getHTMLIcon() template.HTML {
+	return template.HTML(s.htmlIcon)
+}
+
+func (s social) String() string {
+	return s.display
+}
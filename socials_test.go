@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinkSocials(t *testing.T) {
+	c := config{Platforms: []platform{
+		{Name: "Codeberg", URL: "https://codeberg.org/{username}", Icon: `\faGit`, HTMLIcon: "<b>cb</b>"},
+		{Name: "github", Display: "gh/{username}"},
+	}}
+	tests := []struct {
+		s                  social
+		url, display, icon string
+	}{
+		{social{Platform: "GitHub", Username: "jane"}, "https://www.github.com/jane", "gh/jane", `\faGithub`},
+		{social{Platform: "stack-overflow", Username: "42"}, "https://stackoverflow.com/users/42", "stackoverflow.com/users/42", `\faStackOverflow`},
+		{social{Platform: "twitter", Username: "jane"}, "https://x.com/jane", "@jane", `\faTwitter`},
+		{social{Platform: "Mastodon", Username: "@jane@hachyderm.io"}, "https://hachyderm.io/@jane", "@jane@hachyderm.io", `\faMastodon`},
+		{social{Platform: "scholar", Username: "abc"}, "https://scholar.google.com/citations?user=abc", "Google Scholar", `\faGraduationCap`},
+		{social{Platform: "Codeberg", Username: "jane"}, "https://codeberg.org/jane", "codeberg.org/jane", `\faGit`},
+		{social{Platform: "Bluesky", URL: "https://bsky.app/profile/jane/"}, "https://bsky.app/profile/jane/", "bsky.app/profile/jane", ""},
+		{social{Platform: "Website", Username: "jane.dev", URL: "https://www.jane.dev"}, "https://www.jane.dev", "jane.dev", `\faGlobe`},
+		{social{Platform: "Myspace", Username: "jane"}, "", "jane@Myspace", ""},
+	}
+	r := resume{}
+	for _, tt := range tests {
+		r.Info.Socials = append(r.Info.Socials, tt.s)
+	}
+	if err := r.linkSocials(c); err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		s := r.Info.Socials[i]
+		if s.getURL() != tt.url || s.String() != tt.display || s.getIcon() != tt.icon {
+			t.Errorf("%s %s: url %q, display %q, icon %q, want %q, %q, %q", tt.s.Platform, tt.s.Username, s.getURL(), s.String(), s.getIcon(), tt.url, tt.display, tt.icon)
+		}
+	}
+	if got := r.Info.Socials[5].getHTMLIcon(); got != "<b>cb</b>" {
+		t.Errorf("HTML icon = %q", got)
+	}
+
+	r.Info.Name = "Jane Doe"
+	tex := renderTest(t, &r, nil, "resume")
+	for _, want := range []string{`\href{https://www.github.com/jane}{\faGithub \, gh/jane}`, `\, jane@Myspace\qquad`} {
+		if !strings.Contains(tex, want) {
+			t.Errorf("header lacks %q:\n%s", want, tex)
+		}
+	}
+}
+
+func TestPlatformsErrors(t *testing.T) {
+	tests := []struct {
+		p    platform
+		want string
+	}{
+		{platform{URL: "https://codeberg.org/{username}"}, `social platform with url "https://codeberg.org/{username}" has no name`},
+		{platform{Name: "Codeberg"}, "social platform Codeberg has no url"},
+	}
+	for _, tt := range tests {
+		_, err := config{Platforms: []platform{tt.p}}.platforms()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: error = %v, want %q", tt.p, err, tt.want)
+		}
+	}
+}
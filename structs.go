@@ -7,197 +7,295 @@ import (
 	"strings"
 	"time"
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 	yaml "gopkg.in/yaml.v3"
 )
 
 type config struct {
-	BaseFile       string `yaml:"base" form:"file; title=Base Resume File; desc=The resume that will be used as a basis for missing information\nLeave empty to ignore; ext=yml"`
-	TemplateDir    string `yaml:"template" form:"file; title=Template Directory; desc=The directory containing resume templates;ext=tmpl"`
-	TexDir         string `yaml:"tex" form:"dir; title=TeX Output Directory; desc=The directory where TeX files will be generated\nLeave empty to auto create ./tex directory"`
-	PdfDir         string `yaml:"pdf_dir" form:"dir; title=PDF Output Directory; desc=The directory where PDF files will be saved\nLeave empty to auto create ./pdf directory"`
-	CoverFile      string `yaml:"cover_file" form:"input; title=Cover Letter File Name; desc=The name of the generated cover letter file\nDefault option with autogenerate the name; placeholder=default"`
-	PdfFile        string `yaml:"pdf" form:"input; title=PDF File Name; desc=The name of the generated PDF file\nDefault option will autogenerate the name; placeholder=default"`
-	Track          bool   `yaml:"track" form:"confirm; title=Track changes in Obsidian"`
-	KanbanFile     string `yaml:"kanban" form:"file; title=Kanban Board; desc=The Markdown file for your Kanban board; ext=md"`
-	KanbanListName string `yaml:"kanban_list_name" form:"input; title=Kanban List Name; desc=The name of the list in the Kanban board that new jobs will be added under; placeholder=To Apply"`
-	Order          string `yaml:"order" form:"input; title=Default Resume Section Order; desc=Enter the order of sections. Missing section will be omitted:\n\t[e]ducation, e[x]perience, [p]rojects, [s]kills, [c]ertifications, cus[t]om, su[m]mary\nEnter none to be prompted everytime; placeholder=none"`
-	Cover          bool   `yaml:"cover" form:"confirm; title=Generate a Cover Letter"`
-	Show           bool   `yaml:"show" form:"confirm; title=Show PDF after creation"`
+	BaseFile        string     `yaml:"base" form:"file; title=Base Resume File; desc=The resume that will be used as a basis for missing information\nLeave empty to ignore; ext=yml"`
+	TemplateDir     string     `yaml:"template" form:"file; title=Template Directory; desc=The directory containing resume templates. Leave empty to use the built-in templates;ext=tmpl"`
+	Pack            string     `yaml:"pack" form:"input; title=Template Pack; desc=The name of the template pack to use, see the templates list command\nLeave empty to use the built-in pack; placeholder=default"`
+	PackDir         string     `yaml:"pack_dir" form:"dir; title=Template Pack Directory; desc=The directory containing the installed template packs, one per sub directory"`
+	TexDir          string     `yaml:"tex" form:"dir; title=TeX Output Directory; desc=The directory where TeX files will be generated\nLeave empty to auto create ./tex directory"`
+	PdfDir          string     `yaml:"pdf_dir" form:"dir; title=PDF Output Directory; desc=The directory where PDF files will be saved\nLeave empty to auto create ./pdf directory"`
+	LogDir          string     `yaml:"log_dir" form:"dir; title=Log Directory; desc=The directory where the TeX log is kept when a build fails\nLeave empty to use the TeX output directory"`
+	CoverFile       string     `yaml:"cover_file" form:"input; title=Cover Letter File Name; desc=The name of the generated cover letter file\nDefault option with autogenerate the name; placeholder=default"`
+	PdfFile         string     `yaml:"pdf" form:"input; title=PDF File Name; desc=The name of the generated PDF file\nDefault option will autogenerate the name; placeholder=default"`
+	Track           bool       `yaml:"track" form:"confirm; title=Track changes in Obsidian"`
+	KanbanFile      string     `yaml:"kanban" form:"file; title=Kanban Board; desc=The Markdown file for your Kanban board; ext=md"`
+	KanbanListName  string     `yaml:"kanban_list_name" form:"input; title=Kanban List Name; desc=The name of the list in the Kanban board that new jobs will be added under; placeholder=To Apply"`
+	Engine          string     `yaml:"engine" schema:"enum=|pdflatex|xelatex|lualatex|latexmk|latexmk:pdflatex|latexmk:xelatex|latexmk:lualatex|tectonic" form:"input; title=TeX Engine; desc=The program that compiles the TeX files: pdflatex, xelatex, lualatex, latexmk or tectonic\nUse latexmk:xelatex or latexmk:lualatex to run latexmk with another engine\nLeave empty to use the engine of the template pack; placeholder=pdflatex"`
+	Order           string     `yaml:"order" form:"input; title=Default Resume Section Order; desc=Enter the order of sections. Missing section will be omitted:\n\t{sections}\nSeparate names with commas to place custom sections by key, e.g. x,publications,e\nEnter none to be prompted everytime; placeholder=none"`
+	Cover           bool       `yaml:"cover" form:"confirm; title=Generate a Cover Letter"`
+	Show            bool       `yaml:"show" form:"confirm; title=Show PDF after creation"`
+	HTML            bool       `yaml:"html" form:"confirm; title=Generate an HTML resume alongside the PDF"`
+	Tags            string     `yaml:"tags" form:"input; title=Default Tags; desc=Comma separated tags used to select bullets and entries\nLeave empty to include everything; placeholder=backend,go"`
+	ExcludeUntagged bool       `yaml:"exclude_untagged" form:"confirm; title=Exclude untagged entries when filtering by tags"`
+	Theme           theme      `yaml:"theme,omitempty"`      // Visual settings of the templates (Optional)
+	Sections        []section  `yaml:"sections,omitempty"`   // Sections added to the built-in ones, or changing the built-in section with the same code (Optional)
+	Platforms       []platform `yaml:"platforms,omitempty"`  // Social platforms added to the built-in ones, or changing the built-in platform with the same name (Optional)
+	GapMonths       int        `yaml:"gap_months,omitempty"` // Months without work or study that lint reports as a gap, 3 when empty (Optional) Example: 6
+	Region          string     `yaml:"region" schema:"pattern=^([A-Za-z]{2})?$" form:"input; title=Phone Region; desc=Two letter country code of the phone numbers written without a country code\nNumbers from the region are shown in national format, others in international format; placeholder=US"`
 }
 
 type resume struct {
-	Job            job             `yaml:"job"`            // Job application details (Optional)
-	Info           info            `yaml:"information"`    // Information of the Person in the Resume (Required)
-	Education      []school        `yaml:"education"`      // Education of the Person in the Resume (Required)
-	Experiences    []experience    `yaml:"experience"`     // Experiences of the Person in the Resume (Required)
-	Projects       []project       `yaml:"projects"`       // Projects of the Person in the Resume (Optional)
-	Skills         []skill         `yaml:"skills"`         // Skills of the Person in the Resume (Optional)
-	Certifications []certification `yaml:"certifications"` // Certifications of the Person in the Resume (Optional)
-	Custom         custom          `yaml:"custom"`         // Custom Section of the Person in the Resume (Optional)
-	Summary        summary         `yaml:"summary"`        // Summary Section of the Person in the Resume (Optional)
-	CoverLetter    coverLetter     `yaml:"cover_letter"`   // Cover Letter of the Person in the Resume (Optional)
+	Job            job              `yaml:"job,omitempty"`                // Job application details (Optional)
+	Info           info             `yaml:"information,omitempty"`        // Information of the Person in the Resume (Required)
+	Education      []school         `yaml:"education,omitempty"`          // Education of the Person in the Resume (Required)
+	Experiences    []experience     `yaml:"experience,omitempty"`         // Experiences of the Person in the Resume (Required)
+	Projects       []project        `yaml:"projects,omitempty"`           // Projects of the Person in the Resume (Optional)
+	Skills         []skill          `yaml:"skills,omitempty"`             // Skills of the Person in the Resume (Optional)
+	Certifications []certification  `yaml:"certifications,omitempty"`     // Certifications of the Person in the Resume (Optional)
+	Publications   []publication    `yaml:"publications,omitempty"`       // Publications of the Person in the Resume (Optional)
+	Awards         []award          `yaml:"awards,omitempty"`             // Awards of the Person in the Resume (Optional)
+	Volunteer      []volunteer      `yaml:"volunteer,omitempty"`          // Volunteer Work of the Person in the Resume (Optional)
+	Languages      []spokenLanguage `yaml:"languages,omitempty"`          // Spoken Languages of the Person in the Resume (Optional)
+	Interests      []interest       `yaml:"interests,omitempty"`          // Interests of the Person in the Resume (Optional)
+	References     []reference      `yaml:"references,omitempty"`         // References of the Person in the Resume (Optional)
+	Custom         customSections   `yaml:"custom,omitempty"`             // Custom Sections of the Person in the Resume, placed in the order by key (Optional)
+	Summary        summary          `yaml:"summary,omitempty"`            // Summary Section of the Person in the Resume (Optional)
+	CoverLetter    coverLetter      `yaml:"cover_letter,omitempty"`       // Cover Letter of the Person in the Resume (Optional)
+	Theme          theme            `yaml:"theme,omitempty" sanitize:"-"` // Visual settings overriding the configured theme (Optional)
+	origin         map[string]int   // index in the resume file of the entries kept by filterTags
 }
 
 type job struct {
-	Title    string `yaml:"title"`    // Title of the Job (Required) Example: Software Engineer
-	Company  string `yaml:"company"`  // Company of the Job (Required) Example: Google
-	Location string `yaml:"location"` // Location of the Job (Required) Example: Mountain View, CA
-	URL      string `yaml:"url"`      // URL of the Job (Optional) Example: https://www.google.com
-	UUID     string
+	Title    string   `yaml:"title,omitempty"`                                  // Title of the Job (Required) Example: Software Engineer
+	Company  string   `yaml:"company,omitempty"`                                // Company of the Job (Required) Example: Google
+	Location string   `yaml:"location,omitempty"`                               // Location of the Job (Required) Example: Mountain View, CA
+	URL      string   `yaml:"url,omitempty" schema:"format=uri" sanitize:"url"` // URL of the Job (Optional) Example: https://www.google.com
+	Tags     []string `yaml:"tags,omitempty"`                                   // Tags used to select entries for the Job (Optional) Example: [backend, go]
+	UUID     string   `yaml:"uuid,omitempty" schema:"-"`
 }
 
 type info struct {
-	Name        string   `yaml:"name"`        // Name of the Person in the Resume (Required) Example: John Decode
-	Citizenship string   `yaml:"citizenship"` // Citizenship of the Person in the Resume (Optional) Example: United States
-	Address     address  `yaml:"address"`     // Address of the Person in the Resume (Optional)
-	Email       string   `yaml:"email"`       // Email of the Person in the Resume (Required) Example: name@example.com
-	Phone       phone    `yaml:"phone"`       // Phone of the Person in the Resume (Required) Example: 1234567890
-	Socials     []social `yaml:"socials"`     // Social Media of the Person in the Resume (Optional)
+	Name        string   `yaml:"name,omitempty"`                                       // Name of the Person in the Resume (Required) Example: John Decode
+	Citizenship string   `yaml:"citizenship,omitempty"`                                // Citizenship of the Person in the Resume (Optional) Example: United States
+	Address     address  `yaml:"address,omitempty"`                                    // Address of the Person in the Resume (Optional)
+	Email       string   `yaml:"email,omitempty" schema:"format=email" sanitize:"url"` // Email of the Person in the Resume (Required) Example: name@example.com
+	Phone       phone    `yaml:"phone,omitempty"`                                      // Phone of the Person in the Resume (Required) Example: 1234567890
+	Socials     []social `yaml:"socials,omitempty"`                                    // Social Media of the Person in the Resume (Optional)
 }
 
 type address struct {
-	Street string `yaml:"street"` // Street of the Address (Required) Example: 123 Main St
-	City   string `yaml:"city"`   // City of the Address (Required) Example: New York
-	State  string `yaml:"state"`  // State of the Address (Required) Example: NY
-	Zip    string `yaml:"zip"`    // Zip of the Address (Required) Example: 10001
+	Street string `yaml:"street,omitempty"` // Street of the Address (Optional) Example: 123 Main St
+	City   string `yaml:"city,omitempty"`   // City of the Address (Required) Example: New York
+	State  string `yaml:"state,omitempty"`  // State of the Address (Optional) Example: NY
+	Zip    string `yaml:"zip,omitempty"`    // Zip of the Address (Optional) Example: 10001
 }
 
 type phone struct {
-	Number string `yaml:"phone"` // Number of the Phone (Required) Example: 1234567890
+	Number  string `yaml:"phone,omitempty"` // Number of the Phone (Required) Example: 1234567890
+	e164    string // set by parse
+	display string
 }
 
 type social struct {
-	Platform string `yaml:"platform"` // Platform of the Social Media (Optional) Example: GitHub
-	Username string `yaml:"username"` // Username of the Person in the Social Media (Optional) Example: johndecode
-	url      string
+	Platform string `yaml:"platform,omitempty"`                               // Platform of the Social Media (Optional) Example: GitHub
+	Username string `yaml:"username,omitempty"`                               // Username of the Person in the Social Media (Optional) Example: johndecode
+	URL      string `yaml:"url,omitempty" schema:"format=uri" sanitize:"url"` // URL of the Profile, for platforms that are not configured (Optional) Example: https://codeberg.org/johndecode
+	url      string // resolved by link
 	icon     string
+	htmlIcon string
+	display  string
 }
 
 type school struct {
-	Name      string `yaml:"name"`       // Name of the School (Required) Example: University of Science
-	StartDate date   `yaml:"start_date"` // Start Date of the School (Required) Example: 2018-08-01
-	EndDate   date   `yaml:"end_date"`   // End Date of the School (Required) Example: 2022-05-01
-	Major     string `yaml:"major"`      // Major of the School (Required) Example: Computer Science
-	Minor     string `yaml:"minor"`      // Minor of the School (Optional) Example: Mathematics
-	Location  string `yaml:"location"`   // Location of the School (Required) Example: New York, NY
+	Name       string   `yaml:"name,omitempty"`       // Name of the School (Required) Example: University of Science
+	StartDate  date     `yaml:"start_date,omitempty"` // Start Date of the School (Required) Example: 2018-08-01
+	EndDate    date     `yaml:"end_date,omitempty"`   // End Date of the School (Required) Example: 2022-05-01
+	Major      string   `yaml:"major,omitempty"`      // Major of the School (Required) Example: Computer Science
+	Minor      string   `yaml:"minor,omitempty"`      // Minor of the School (Optional) Example: Mathematics
+	Location   string   `yaml:"location,omitempty"`   // Location of the School (Required) Example: New York, NY
+	GPA        string   `yaml:"gpa,omitempty"`        // Grade Point Average, with its scale (Optional) Example: 3.8/4.0
+	Honors     []string `yaml:"honors,omitempty"`     // Honors received at the School (Optional) Example: [Magna Cum Laude]
+	Coursework []string `yaml:"coursework,omitempty"` // Relevant Courses taken (Optional) Example: [Algorithms, Operating Systems]
+	Thesis     string   `yaml:"thesis,omitempty"`     // Title of the Thesis (Optional) Example: Fast Typesetting of Resumes
+	Tags       []string `yaml:"tags,omitempty"`       // Tags used to select the School (Optional) Example: [backend, go]
 }
 
 type date struct {
-	time time.Time `yaml:"start_date,issue_date,expiration_date,end_date"` // Time of the Date (Optional) Example: 2022-05-01
-	text string    `yaml:"text"`                                           // Text of the Date (Optional) Example: Present
+	time      time.Time `yaml:"start_date,issue_date,expiration_date,end_date"` // Time of the Date (Optional) Example: 2022-05-01
+	text      string    `yaml:"text"`                                           // Text of the Date (Optional) Example: Present
+	precision datePrecision
+	ongoing   bool // Present and its synonyms
+	expected  bool // written as expected: 2026-05, the date has not happened yet
 }
 
 type experience struct {
-	Company     string   `yaml:"company"`     // Company of the Job (Required) Example: Google
-	Title       string   `yaml:"title"`       // Title of the Job (Required) Example: Software Engineer
-	StartDate   date     `yaml:"start_date"`  // Start Date of the Job (Required) Example: 2022-05-01
-	EndDate     date     `yaml:"end_date"`    // End Date of the Job or "Present" (Optional) Example: 2022-05-01
-	Location    string   `yaml:"location"`    // Location of the Job (Required) Example: Mountain View, CA
-	Description []string `yaml:"description"` // Description of the Job (Required)
+	Company     string   `yaml:"company,omitempty"`     // Company of the Job (Required) Example: Google
+	Title       string   `yaml:"title,omitempty"`       // Title of the Job (Required) Example: Software Engineer
+	StartDate   date     `yaml:"start_date,omitempty"`  // Start Date of the Job (Required) Example: 2022-05-01
+	EndDate     date     `yaml:"end_date,omitempty"`    // End Date of the Job or "Present" (Optional) Example: 2022-05-01
+	Location    string   `yaml:"location,omitempty"`    // Location of the Job (Required) Example: Mountain View, CA
+	Description []bullet `yaml:"description,omitempty"` // Description of the Job (Required)
+	Roles       []role   `yaml:"roles,omitempty"`       // Positions held at the Company, replacing the title, dates and description (Optional)
+	Tags        []string `yaml:"tags,omitempty"`        // Tags used to select the Job (Optional) Example: [backend, go]
+}
+
+// role is one of several positions held at the company of an experience
+type role struct {
+	Title       string   `yaml:"title,omitempty"`       // Title of the Role (Required) Example: Senior Software Engineer
+	StartDate   date     `yaml:"start_date,omitempty"`  // Start Date of the Role (Required) Example: 2022-05-01
+	EndDate     date     `yaml:"end_date,omitempty"`    // End Date of the Role or "Present" (Optional) Example: 2023-05-01
+	Location    string   `yaml:"location,omitempty"`    // Location of the Role, when it differs from the Job (Optional) Example: New York, NY
+	Description []bullet `yaml:"description,omitempty"` // Description of the Role (Optional)
+	Tags        []string `yaml:"tags,omitempty"`        // Tags used to select the Role (Optional) Example: [backend, go]
+}
+
+// bullet is a line of a description. It is either plain text or a mapping with text and tags
+type bullet struct {
+	Text string   `yaml:"text" sanitize:"markdown"` // Text of the Bullet (Required) Example: Reduced latency by 40%
+	Tags []string `yaml:"tags,omitempty"`           // Tags used to select the Bullet (Optional) Example: [backend, go]
 }
 
 type project struct {
-	Name         string   `yaml:"name"`         // Name of the Project (Required) Example: Resume Builder
-	Description  []string `yaml:"description"`  // Description of the Project (Required) Example: A tool to generate resumes
-	Technologies []string `yaml:"technologies"` // Technologies used in the Project (Required) Example: [Go, LaTeX]
+	Name         string   `yaml:"name,omitempty"`                                          // Name of the Project (Required) Example: Resume Builder
+	Description  []string `yaml:"description,omitempty" sanitize:"markdown"`               // Description of the Project (Required) Example: A tool to generate resumes
+	Technologies []string `yaml:"technologies,omitempty"`                                  // Technologies used in the Project (Required) Example: [Go, LaTeX]
+	Role         string   `yaml:"role,omitempty"`                                          // Role of the Person in the Project (Optional) Example: Maintainer
+	URL          string   `yaml:"url,omitempty" schema:"format=uri" sanitize:"url"`        // URL of the Project (Optional) Example: https://resume.example.com
+	Repository   string   `yaml:"repository,omitempty" schema:"format=uri" sanitize:"url"` // URL of the source code of the Project (Optional) Example: https://github.com/johndecode/resume
+	StartDate    date     `yaml:"start_date,omitempty"`                                    // Start Date of the Project (Optional) Example: 2022-05-01
+	EndDate      date     `yaml:"end_date,omitempty"`                                      // End Date of the Project or "Present" (Optional) Example: 2022-08-01
+	Tags         []string `yaml:"tags,omitempty"`                                          // Tags used to select the Project (Optional) Example: [backend, go]
 }
 
 type skill struct {
-	Name     string   `yaml:"name"`     // Name of the Skill (Required) Example: Programming
-	Keywords []string `yaml:"keywords"` // Keywords of the Skill (Required) Example: [Go, Python]
+	Name     string   `yaml:"name,omitempty"`     // Name of the Skill (Required) Example: Programming
+	Keywords []string `yaml:"keywords,omitempty"` // Keywords of the Skill (Required) Example: [Go, Python]
+	Tags     []string `yaml:"tags,omitempty"`     // Tags used to select the Skill (Optional) Example: [backend, go]
 }
 
 type certification struct {
-	Name           string `yaml:"name"`            // Name of the Certification (Required) Example: AWS Certified Solutions Architect
-	IssuingOrg     string `yaml:"issuing_org"`     // Issuing Organization of the Certification (Required) Example: Amazon Web Services
-	URL            string `yaml:"url"`             // URL of the Certification (Optional) Example: https://www.aws.com
-	IssueDate      date   `yaml:"issue_date"`      // Issue Date of the Certification (Required) Example: 2022-05-01
-	ExpirationDate date   `yaml:"expiration_date"` // Expiration Date of the Certification (Optional) Example: 2022-05-01
+	Name           string   `yaml:"name,omitempty"`                                   // Name of the Certification (Required) Example: AWS Certified Solutions Architect
+	IssuingOrg     string   `yaml:"issuing_org,omitempty"`                            // Issuing Organization of the Certification (Required) Example: Amazon Web Services
+	URL            string   `yaml:"url,omitempty" schema:"format=uri" sanitize:"url"` // URL of the Certification (Optional) Example: https://www.aws.com
+	IssueDate      date     `yaml:"issue_date,omitempty"`                             // Issue Date of the Certification (Required) Example: 2022-05-01
+	ExpirationDate date     `yaml:"expiration_date,omitempty"`                        // Expiration Date of the Certification (Optional) Example: 2022-05-01
+	CredentialID   string   `yaml:"credential_id,omitempty"`                          // Credential ID of the Certification (Optional) Example: AWS-ASA-12345
+	Tags           []string `yaml:"tags,omitempty"`                                   // Tags used to select the Certification (Optional) Example: [backend, go]
 }
 
-type custom struct {
-	Title       string   `yaml:"title"`       // Title of the Custom Section (Optional) Example: Hobbies
-	Description string   `yaml:"description"` // Description of the Custom Section (Optional) Example: Playing Chess
-	Body        []string `yaml:"body"`        // Body of the Custom Section (Optional)
+type publication struct {
+	Title       string   `yaml:"title,omitempty"`                                                          // Title of the Publication (Required) Example: Scaling Resume Builds
+	Authors     []string `yaml:"authors,omitempty"`                                                        // Authors of the Publication, in order (Optional) Example: [J. Decode, A. Smith]
+	Venue       string   `yaml:"venue,omitempty"`                                                          // Journal, conference or publisher of the Publication (Optional) Example: GopherCon
+	Date        date     `yaml:"date,omitempty"`                                                           // Release Date of the Publication (Optional) Example: 2023-05-01
+	DOI         string   `yaml:"doi,omitempty" schema:"pattern=^10\\.[0-9]{4}[0-9]*/\\S+$" sanitize:"url"` // DOI of the Publication (Optional) Example: 10.1145/3368089.3409740
+	URL         string   `yaml:"url,omitempty" schema:"format=uri" sanitize:"url"`                         // URL of the Publication (Optional) Example: https://example.com/paper.pdf
+	Description string   `yaml:"description,omitempty" sanitize:"markdown"`                                // Summary of the Publication (Optional) Example: Cut build times by half
+	Tags        []string `yaml:"tags,omitempty"`                                                           // Tags used to select the Publication (Optional) Example: [backend, go]
 }
 
-type summary struct {
-	Title string `yaml:"title"` // Title of the Summary Section (Optional)
-	Body  string `yaml:"body"`  // Body of the Summary Section (Optional)
+type award struct {
+	Title       string   `yaml:"title,omitempty"`                           // Title of the Award (Required) Example: Dean's List
+	Awarder     string   `yaml:"awarder,omitempty"`                         // Organization giving the Award (Optional) Example: University of Science
+	Date        date     `yaml:"date,omitempty"`                            // Date of the Award (Optional) Example: 2021-05-01
+	Description string   `yaml:"description,omitempty" sanitize:"markdown"` // Description of the Award (Optional) Example: Top 5% of the class
+	Tags        []string `yaml:"tags,omitempty"`                            // Tags used to select the Award (Optional) Example: [backend, go]
 }
 
-type coverLetter struct {
-	Company  string `yaml:"company"`  // Company of the Cover Letter (Optional) Example: Google
-	Greeting string `yaml:"greeting"` // Greeting of the Cover Letter (Required) Example: Dear Hiring Manager,
-	Body     string `yaml:"body"`     // Body of the Cover Letter (Required)
-}
-
-func (s social) getURL() string {
-	p, u := strings.ToLower(s.Platform), strings.ToLower(s.Username)
-	switch p {
-	case "github":
-		return fmt.Sprintf("%s/%s", githubURL, u)
-	case "linkedin":
-		return fmt.Sprintf("%s/in/%s", linkedinURL, u)
-	}
-	return ""
+type volunteer struct {
+	Organization string   `yaml:"organization,omitempty"`                           // Organization of the Volunteer Work (Required) Example: Red Cross
+	Position     string   `yaml:"position,omitempty"`                               // Position held (Required) Example: Volunteer
+	StartDate    date     `yaml:"start_date,omitempty"`                             // Start Date of the Volunteer Work (Required) Example: 2020-01-01
+	EndDate      date     `yaml:"end_date,omitempty"`                               // End Date of the Volunteer Work or "Present" (Optional) Example: 2021-05-01
+	Location     string   `yaml:"location,omitempty"`                               // Location of the Volunteer Work (Optional) Example: Boston, MA
+	URL          string   `yaml:"url,omitempty" schema:"format=uri" sanitize:"url"` // URL of the Organization (Optional) Example: https://www.redcross.org
+	Description  []bullet `yaml:"description,omitempty"`                            // Description of the Volunteer Work (Optional)
+	Tags         []string `yaml:"tags,omitempty"`                                   // Tags used to select the Volunteer Work (Optional) Example: [backend, go]
 }
 
-func (s social) getIcon() string {
-	p := strings.ToLower(s.Platform)
-	switch p {
-	case "github":
-		return "\\faGithub"
-	case "linkedin":
-		return "\\faLinkedin"
-	}
-	return ""
+type spokenLanguage struct {
+	Name  string   `yaml:"name,omitempty"`  // Name of the Language (Required) Example: Spanish
+	Level string   `yaml:"level,omitempty"` // Proficiency, in words or as a CEFR level (Optional) Example: C1
+	Tags  []string `yaml:"tags,omitempty"`  // Tags used to select the Language (Optional) Example: [backend, go]
 }
 
-func inFuture(d date) bool {
-	if d.text != "" {
-		return true
-	}
-	return d.time.After(time.Now())
+type interest struct {
+	Name     string   `yaml:"name,omitempty"`     // Name of the Interest (Required) Example: Music
+	Keywords []string `yaml:"keywords,omitempty"` // Keywords of the Interest (Optional) Example: [Piano, Jazz]
+	Tags     []string `yaml:"tags,omitempty"`     // Tags used to select the Interest (Optional) Example: [backend, go]
+}
+
+type reference struct {
+	Name     string   `yaml:"name,omitempty"`                          // Name of the Reference (Required) Example: Jane Smith
+	Position string   `yaml:"position,omitempty"`                      // Position of the Reference (Optional) Example: Engineering Manager
+	Company  string   `yaml:"company,omitempty"`                       // Company of the Reference (Optional) Example: Google
+	Email    string   `yaml:"email,omitempty" schema:"format=email"`   // Email of the Reference (Optional) Example: jane@example.com
+	Phone    phone    `yaml:"phone,omitempty"`                         // Phone of the Reference (Optional) Example: 1234567890
+	Text     string   `yaml:"reference,omitempty" sanitize:"markdown"` // What the Reference says about the Person (Optional) Example: A pleasure to work with
+	Tags     []string `yaml:"tags,omitempty"`                          // Tags used to select the Reference (Optional) Example: [backend, go]
+}
+
+type custom struct {
+	Key         string        `yaml:"key,omitempty" schema:"pattern=^[a-z0-9][a-z0-9_-]+$" sanitize:"-"` // Key that places the section in the order, the section is placed by t when empty (Optional) Example: publications
+	Title       string        `yaml:"title,omitempty"`                                                   // Title of the Custom Section (Optional) Example: Hobbies
+	Description string        `yaml:"description,omitempty" sanitize:"markdown"`                         // Description of the Custom Section (Optional) Example: Playing Chess
+	Body        []string      `yaml:"body,omitempty" sanitize:"markdown"`                                // Body of the Custom Section (Optional)
+	Entries     []customEntry `yaml:"entries,omitempty"`                                                 // Entries of the Custom Section, rendered before the body (Optional)
+}
+
+// customSections is the list of custom sections. A single mapping, the
+// format before sections could be named, is read as a list of one.
+type customSections []custom
+
+type customEntry struct {
+	Name        string   `yaml:"name,omitempty"`                                   // Name of the Entry (Required) Example: Red Cross
+	Subtitle    string   `yaml:"subtitle,omitempty"`                               // Role, venue or other detail shown under the name (Optional) Example: Volunteer
+	Date        date     `yaml:"date,omitempty"`                                   // Date of the Entry, or its start date with an end date (Optional) Example: 2021-06-01
+	EndDate     date     `yaml:"end_date,omitempty"`                               // End Date of the Entry (Optional) Example: 2022-05-01
+	Location    string   `yaml:"location,omitempty"`                               // Location of the Entry (Optional) Example: Boston, MA
+	URL         string   `yaml:"url,omitempty" schema:"format=uri" sanitize:"url"` // Link of the Entry (Optional) Example: https://www.redcross.org
+	Description []string `yaml:"description,omitempty" sanitize:"markdown"`        // Description of the Entry (Optional) Example: Organized blood drives
+	Tags        []string `yaml:"tags,omitempty"`                                   // Tags used to select the Entry (Optional) Example: [backend, go]
+}
+
+type summary struct {
+	Title string `yaml:"title,omitempty"`                    // Title of the Summary Section (Optional)
+	Body  string `yaml:"body,omitempty" sanitize:"markdown"` // Body of the Summary Section (Optional)
 }
 
-func (t date) String() string {
+type coverLetter struct {
+	Company  string `yaml:"company,omitempty"`  // Company of the Cover Letter (Optional) Example: Google
+	Greeting string `yaml:"greeting,omitempty"` // Greeting of the Cover Letter (Required) Example: Dear Hiring Manager,
+	Body     string `yaml:"body,omitempty"`     // Body of the Cover Letter (Required)
+}
 
-	if t.text != "" {
-		caser := cases.Title(language.English)
-		t.text = caser.String(t.text)
-		return t.text
-	} else {
-		return t.time.Format("Jan 2006")
+// Start returns the start date of the experience, the earliest of its roles
+func (e experience) Start() date {
+	start := e.StartDate
+	for _, r := range e.Roles {
+		if start.IsZero() || (!r.StartDate.IsZero() && r.StartDate.time.Before(start.time)) {
+			start = r.StartDate
+		}
 	}
+	return start
 }
 
-func (p phone) String() string {
-	n := p.Number
-	if len(n) != 10 {
-		return n
+// End returns the end date of the experience, the latest of its roles. An
+// ongoing role, or one without an end date, makes the whole experience
+// ongoing.
+func (e experience) End() date {
+	end := e.EndDate
+	for _, r := range e.Roles {
+		roleEnd := r.EndDate
+		if roleEnd.IsZero() {
+			roleEnd = date{text: "Present", ongoing: true}
+		}
+		switch {
+		case end.ongoing:
+		case end.IsZero() || roleEnd.ongoing || roleEnd.time.After(end.time):
+			end = roleEnd
+		}
 	}
-	return fmt.Sprintf("(%s) %s-%s", n[0:3], n[3:6], n[6:])
+	return end
 }
 
 func (r resume) String() string {
 	return fmt.Sprintf("Name: %s\nEmail: %s\nPhone: %s\n", r.Info.Name, r.Info.Email, r.Info.Phone)
 }
 
-func (s social) String() string {
-	p := strings.ToLower(s.Platform)
-	u := strings.ToLower(s.Username)
-	switch p {
-	case "github":
-		return fmt.Sprintf("%s/%s", githubURL[12:], u)
-	case "linkedin":
-		return fmt.Sprintf("%s/in/%s", linkedinURL[12:], u)
-	}
-	return fmt.Sprintf("%s@%s", u, p)
-}
-
 func (c config) String() string {
 	var sb strings.Builder
 
@@ -235,27 +333,86 @@ func (p *phone) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
-func (t *date) UnmarshalYAML(value *yaml.Node) error {
-	var (
-		s      string
-		layout string
-	)
-	err := value.Decode(&s)
-	if err != nil {
+func (b bullet) String() string {
+	return b.Text
+}
+
+func (b *bullet) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		b.Text, b.Tags = value.Value, nil
+		return nil
+	}
+	type plain bullet
+	return value.Decode((*plain)(b))
+}
+
+func (b bullet) MarshalYAML() (interface{}, error) {
+	if len(b.Tags) == 0 {
+		return b.Text, nil
+	}
+	type plain bullet
+	return plain(b), nil
+}
+
+func (c *customSections) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var single custom
+		if err := value.Decode(&single); err != nil {
+			return err
+		}
+		*c = customSections{single}
+		return nil
+	}
+	var list []custom
+	if err := value.Decode(&list); err != nil {
 		return err
 	}
+	*c = list
+	return nil
+}
 
-	if strings.Contains(s, `/`) {
-		layout = "01/02/2006"
-	} else {
-		layout = "2006-01-02"
+func newBullets(lines []string) []bullet {
+	var bullets []bullet
+	for _, l := range lines {
+		bullets = append(bullets, bullet{Text: l})
 	}
-	parsedTime, err := time.Parse(layout, s)
-	if err != nil {
-		t.text = s
+	return bullets
+}
+
+func bulletText(bullets []bullet) []string {
+	var lines []string
+	for _, b := range bullets {
+		lines = append(lines, b.Text)
+	}
+	return lines
+}
+
+func (p phone) MarshalYAML() (interface{}, error) {
+	return p.Number, nil
+}
+
+func (t *date) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var e struct {
+			Expected string `yaml:"expected"`
+		}
+		if err := value.Decode(&e); err != nil {
+			return err
+		}
+		*t = parseDate(e.Expected)
+		t.expected = true
 		return nil
 	}
-	t.text = ""
-	t.time = parsedTime
+	var s string
+	err := value.Decode(&s)
+	if err != nil {
+		return err
+	}
+	*t = parseDate(s)
 	return nil
 }
+
+// IsZero reports whether the date was left empty so omitempty can skip it
+func (t date) IsZero() bool {
+	return t.text == "" && t.time.IsZero()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestExperienceEnd(t *testing.T) {
+	newRole := func(start, end string) role {
+		return role{StartDate: parseDate(start), EndDate: parseDate(end)}
+	}
+	tests := []struct {
+		name  string
+		e     experience
+		want  string
+		going bool
+	}{
+		{"no roles", experience{EndDate: parseDate("2020-05")}, "May 2020", false},
+		{"latest role", experience{Roles: []role{newRole("2015-01", "2018-03"), newRole("2018-03", "2021-07")}}, "Jul 2021", false},
+		{"ongoing role", experience{Roles: []role{newRole("2015-01", "2018-03"), newRole("2018-03", "Present")}}, "Present", true},
+		{"role without end", experience{Roles: []role{newRole("2015-01", "2018-03"), newRole("2018-03", "")}}, "Present", true},
+		{"current role first", experience{Roles: []role{newRole("2018-03", ""), newRole("2015-01", "2018-03")}}, "Present", true},
+	}
+	for _, tt := range tests {
+		end := tt.e.End()
+		if end.String() != tt.want || end.ongoing != tt.going {
+			t.Errorf("%s: End() = %s (ongoing %v), want %s", tt.name, end, end.ongoing, tt.want)
+		}
+	}
+}
+
+func TestExperienceStart(t *testing.T) {
+	tests := []struct {
+		name string
+		e    experience
+		want string
+	}{
+		{"no roles", experience{StartDate: parseDate("2019-02")}, "Feb 2019"},
+		{"earliest role", experience{Roles: []role{{StartDate: parseDate("2018-03")}, {StartDate: parseDate("2015-01")}}}, "Jan 2015"},
+		{"role without start", experience{Roles: []role{{}, {StartDate: parseDate("2016-06")}}}, "Jun 2016"},
+	}
+	for _, tt := range tests {
+		if got := tt.e.Start().String(); got != tt.want {
+			t.Errorf("%s: Start() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+const testRoles = `information:
+  name: Jane Doe
+experience:
+  - company: Acme
+    location: Remote
+    roles:
+      - title: Senior Engineer
+        start_date: 2018-03
+        end_date: 2021-07
+        description: [Led the API team]
+      - title: Engineer
+        start_date: 2015-01
+        end_date: 2018-03
+        location: Boston, MA
+  - company: Globex
+    title: Intern
+    start_date: 2014-06
+    end_date: 2014-08
+    description: [Wrote tests]
+`
+
+func TestExperienceRoles(t *testing.T) {
+	var r resume
+	if err := yaml.Unmarshal([]byte(testRoles), &r); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.applyTheme(config{}); err != nil {
+		t.Fatal(err)
+	}
+	order, err := sectionRegistry(defaultSections).parseOrder("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tmplType string
+		want     []string
+	}{
+		{"resume", []string{
+			`\textbf{Acme} \textbar{} \textit{\small Remote} \hfill \textbf{Jan 2015 \textendash{} Jul 2021}`,
+			`\textit{Senior Engineer} \hfill \textit{ \small Mar 2018 \textendash{} Jul 2021}`,
+			`\textit{Engineer} \textbar{} \textit{\small Boston, MA} \hfill`,
+			`\item {Led the API team}`,
+			`\textbf{Globex} \hfill \textbf{Jun 2014 \textendash{} Aug 2014}`,
+			`\textit{Intern}`,
+		}},
+		{"html", []string{`<span class="title">Acme</span>`, "Jan 2015 – Jul 2021", "Senior Engineer", "Led the API team", "Globex", "Intern"}},
+	}
+	for _, tt := range tests {
+		out := renderTest(t, &r, order, tt.tmplType)
+		if n := strings.Count(out, "Acme"); n != 1 {
+			t.Errorf("%s: the company is shown %d times", tt.tmplType, n)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output lacks %q:\n%s", tt.tmplType, want, out)
+			}
+		}
+	}
+}
+
+func TestOptionalFields(t *testing.T) {
+	full := resume{
+		Education:      []school{{Name: "State University", GPA: "3.8/4.0", Honors: []string{"Magna Cum Laude"}, Coursework: []string{"Algorithms"}, Thesis: "Fast Typesetting"}},
+		Projects:       []project{{Name: "Site", URL: "https://example.com", Repository: "https://github.com/jane/site", Role: "Maintainer", StartDate: parseDate("2022-05")}},
+		Certifications: []certification{{Name: "CKA", IssuingOrg: "CNCF", CredentialID: "LF-123"}},
+	}
+	bare := resume{
+		Education:      []school{{Name: "State University"}},
+		Projects:       []project{{Name: "Site"}},
+		Certifications: []certification{{Name: "CKA", IssuingOrg: "CNCF"}},
+	}
+	order, err := sectionRegistry(defaultSections).parseOrder("epc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tmplType string
+		fields   []string // text shown only when the fields are set
+	}{
+		{"resume", []string{"GPA: 3.8/4.0 \\textbar{} Magna Cum Laude", `\textbf{Coursework:} Algorithms`, `\textbf{Thesis:} \textit{Fast Typesetting}`, `\href{https://example.com}{\textbf{Site}}`, `\href{https://github.com/jane/site}{\small\faCodeBranch}`, `\textit{Maintainer}`, "May 2022", "ID: LF-123"}},
+		{"html", []string{"GPA: 3.8/4.0", "Magna Cum Laude", "Coursework:", "Fast Typesetting", `href="https://example.com"`, `href="https://github.com/jane/site"`, "Maintainer", "May 2022", "LF-123"}},
+	}
+	for _, tt := range tests {
+		for _, r := range []*resume{&full, &bare} {
+			if err := r.applyTheme(config{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		withFields, without := renderTest(t, &full, order, tt.tmplType), renderTest(t, &bare, order, tt.tmplType)
+		for _, want := range tt.fields {
+			if !strings.Contains(withFields, want) {
+				t.Errorf("%s output lacks %q:\n%s", tt.tmplType, want, withFields)
+			}
+		}
+		for _, label := range []string{"GPA", "Coursework", "Thesis", "faCodeBranch", "ID:"} {
+			if strings.Contains(without, label) {
+				t.Errorf("%s output shows %s for an empty field:\n%s", tt.tmplType, label, without)
+			}
+		}
+	}
+}
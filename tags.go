@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tagFilter selects the entries of a resume whose tags match any of the wanted tags
+type tagFilter struct {
+	tags            []string
+	excludeUntagged bool // drop entries without tags instead of always including them
+}
+
+func newTagFilter(excludeUntagged bool, lists ...string) tagFilter {
+	f := tagFilter{excludeUntagged: excludeUntagged}
+	seen := map[string]bool{}
+	for _, list := range lists {
+		for _, t := range strings.Split(list, ",") {
+			t = strings.ToLower(strings.TrimSpace(t))
+			if t != "" && !seen[t] {
+				seen[t] = true
+				f.tags = append(f.tags, t)
+			}
+		}
+	}
+	return f
+}
+
+func (f tagFilter) active() bool {
+	return len(f.tags) > 0
+}
+
+func (f tagFilter) match(tags []string) bool {
+	if len(tags) == 0 {
+		return !f.excludeUntagged
+	}
+	for _, t := range tags {
+		for _, want := range f.tags {
+			if strings.EqualFold(strings.TrimSpace(t), want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterTags prunes every list of tagged entries in the resume, including
+// the description bullets of each entry, using the tags from the config and
+// the job block. Nothing is pruned when no tags are selected. The index each
+// kept entry had in the resume file is recorded for sourcePath.
+func (r *resume) filterTags(c config) {
+	f := newTagFilter(c.ExcludeUntagged, c.Tags, strings.Join(r.Job.Tags, ","))
+	if !f.active() {
+		return
+	}
+	r.origin = map[string]int{}
+	pruneTagged(reflect.ValueOf(r).Elem(), f, "", r.origin)
+
+	// An experience whose roles were all pruned has nothing left to show
+	var kept []experience
+	var from []int
+	for i, e := range r.Experiences {
+		if e.Title != "" || len(e.Roles) > 0 {
+			kept = append(kept, e)
+			from = append(from, i)
+		}
+	}
+	r.Experiences = kept
+	renumber(r.origin, "experience", from)
+}
+
+// pruneTagged filters the tagged lists under v, at being the path of v in
+// the pruned resume. origin maps the path of every kept entry, e.g.
+// experience[0], to its index before pruning.
+func pruneTagged(v reflect.Value, f tagFilter, at string, origin map[string]int) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" || field.Name == "Job" {
+				continue
+			}
+			pruneTagged(v.Field(i), f, joinPath(at, yamlName(field)), origin)
+		}
+	case reflect.Slice:
+		if _, ok := tagsField(v.Type().Elem()); ok && v.CanSet() {
+			kept, from := filterSlice(v, f)
+			v.Set(kept)
+			for n, i := range from {
+				origin[fmt.Sprintf("%s[%d]", at, n)] = i
+			}
+		}
+		for i := 0; i < v.Len(); i++ {
+			pruneTagged(v.Index(i), f, fmt.Sprintf("%s[%d]", at, i), origin)
+		}
+	}
+}
+
+// renumber moves the recorded entries under list after the entries of the
+// list were pruned again, from holding the old index of each kept entry
+func renumber(origin map[string]int, list string, from []int) {
+	moved := map[string]int{}
+	for k, v := range origin {
+		rest, ok := strings.CutPrefix(k, list+"[")
+		if !ok {
+			moved[k] = v
+			continue
+		}
+		i, rest, _ := strings.Cut(rest, "]")
+		old, _ := strconv.Atoi(i)
+		for n, o := range from {
+			if o == old {
+				moved[fmt.Sprintf("%s[%d]%s", list, n, rest)] = v
+			}
+		}
+	}
+	for k := range origin {
+		delete(origin, k)
+	}
+	for k, v := range moved {
+		origin[k] = v
+	}
+}
+
+// sourcePath returns the path a field of the pruned resume has in the resume
+// file, e.g. experience[2].description[0] for experience[0].description[0]
+func (r *resume) sourcePath(p string) string {
+	if len(r.origin) == 0 {
+		return p
+	}
+	var out, at strings.Builder
+	for {
+		open := strings.IndexByte(p, '[')
+		if open < 0 {
+			out.WriteString(p)
+			return out.String()
+		}
+		end := strings.IndexByte(p[open:], ']') + open
+		at.WriteString(p[:end+1])
+		index := p[open+1 : end]
+		if i, ok := r.origin[at.String()]; ok {
+			index = strconv.Itoa(i)
+		}
+		out.WriteString(p[:open+1] + index + "]")
+		p = p[end+1:]
+	}
+}
+
+// yamlName is the key of a struct field in the resume file
+func yamlName(f reflect.StructField) string {
+	if name := strings.Split(f.Tag.Get("yaml"), ",")[0]; name != "" {
+		return name
+	}
+	return strings.ToLower(f.Name)
+}
+
+func tagsField(t reflect.Type) (reflect.StructField, bool) {
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	field, ok := t.FieldByName("Tags")
+	return field, ok && field.Type == reflect.TypeOf([]string(nil))
+}
+
+// filterSlice returns the entries of v matching the filter and the index
+// of each in v
+func filterSlice(v reflect.Value, f tagFilter) (reflect.Value, []int) {
+	field, _ := tagsField(v.Type().Elem())
+	kept := reflect.MakeSlice(v.Type(), 0, v.Len())
+	var from []int
+	for i := 0; i < v.Len(); i++ {
+		if f.match(v.Index(i).FieldByIndex(field.Index).Interface().([]string)) {
+			kept = reflect.Append(kept, v.Index(i))
+			from = append(from, i)
+		}
+	}
+	return kept, from
+}
+
+// tagged is the template function form of the tag filter
+// Example: {{range tagged .Projects "backend" "go"}}
+func tagged(items interface{}, tags ...string) (interface{}, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("tagged: expected a list, got %T", items)
+	}
+	if _, ok := tagsField(v.Type().Elem()); !ok {
+		return nil, fmt.Errorf("tagged: %T entries have no tags", items)
+	}
+	f := newTagFilter(false, strings.Join(tags, ","))
+	if !f.active() {
+		return items, nil
+	}
+	kept, _ := filterSlice(v, f)
+	return kept.Interface(), nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testTaggedResume = `job:
+  tags: [Go]
+experience:
+  - company: Acme
+    title: Engineer
+    tags: [backend]
+    description:
+      - Wrote the billing service
+      - text: Tuned the Go runtime
+        tags: [go]
+      - text: Drew the logo
+        tags: [design]
+  - company: Studio
+    title: Designer
+    tags: [design]
+  - company: Globex
+    tags: [frontend, backend]
+    roles:
+      - title: Frontend
+        tags: [frontend]
+      - title: Backend
+        tags: [backend]
+  - company: Hooli
+    roles:
+      - title: Designer
+        tags: [design]
+projects:
+  - name: Untagged
+  - name: Compiler
+    tags: [go]
+`
+
+func loadTaggedResume(t *testing.T) resume {
+	t.Helper()
+	var r resume
+	if err := yaml.Unmarshal([]byte(testTaggedResume), &r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func names[T any](items []T, name func(T) string) []string {
+	var out []string
+	for _, it := range items {
+		out = append(out, name(it))
+	}
+	return out
+}
+
+func TestFilterTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		c           config
+		experiences []string
+		roles       []string // roles of Globex
+		bullets     []string // bullets of Acme
+		projects    []string
+	}{
+		{"job tags only", config{},
+			nil, nil, nil, []string{"Untagged", "Compiler"}},
+		{"config and job tags", config{Tags: " Backend , design"},
+			[]string{"Acme", "Studio", "Globex", "Hooli"}, []string{"Backend"},
+			[]string{"Wrote the billing service", "Tuned the Go runtime", "Drew the logo"}, []string{"Untagged", "Compiler"}},
+		{"exclude untagged", config{Tags: "backend", ExcludeUntagged: true},
+			[]string{"Acme", "Globex"}, []string{"Backend"},
+			[]string{"Tuned the Go runtime"}, []string{"Compiler"}},
+	}
+	for _, tt := range tests {
+		r := loadTaggedResume(t)
+		r.filterTags(tt.c)
+		if got := names(r.Experiences, func(e experience) string { return e.Company }); !reflect.DeepEqual(got, tt.experiences) {
+			t.Errorf("%s: experiences = %q, want %q", tt.name, got, tt.experiences)
+			continue
+		}
+		for _, e := range r.Experiences {
+			switch e.Company {
+			case "Acme":
+				if got := bulletText(e.Description); !reflect.DeepEqual(got, tt.bullets) {
+					t.Errorf("%s: bullets = %q, want %q", tt.name, got, tt.bullets)
+				}
+			case "Globex":
+				if got := names(e.Roles, func(r role) string { return r.Title }); !reflect.DeepEqual(got, tt.roles) {
+					t.Errorf("%s: roles = %q, want %q", tt.name, got, tt.roles)
+				}
+			}
+		}
+		if got := names(r.Projects, func(p project) string { return p.Name }); !reflect.DeepEqual(got, tt.projects) {
+			t.Errorf("%s: projects = %q, want %q", tt.name, got, tt.projects)
+		}
+	}
+}
+
+func TestFilterTagsInactive(t *testing.T) {
+	r := loadTaggedResume(t)
+	r.Job.Tags = nil
+	want := loadTaggedResume(t)
+	want.Job.Tags = nil
+	r.filterTags(config{ExcludeUntagged: true})
+	if !reflect.DeepEqual(r, want) {
+		t.Error("the resume was pruned without any tag selected")
+	}
+}
+
+func TestTagged(t *testing.T) {
+	r := loadTaggedResume(t)
+	got, err := tagged(r.Projects, "GO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := names(got.([]project), func(p project) string { return p.Name }); !reflect.DeepEqual(names, []string{"Untagged", "Compiler"}) {
+		t.Errorf("tagged projects = %q", names)
+	}
+	if got, _ := tagged(r.Projects); !reflect.DeepEqual(got, r.Projects) {
+		t.Error("tagged without tags changed the list")
+	}
+	if _, err := tagged(r.Info, "go"); err == nil {
+		t.Error("tagged accepted a value that is not a list")
+	}
+	if _, err := tagged([]string{"a"}, "go"); err == nil {
+		t.Error("tagged accepted entries without tags")
+	}
+}
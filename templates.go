@@ -0,0 +1,321 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/log"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// The default pack is built into the binary so that it works without a copy
+// of the repository
+//
+//go:embed templates/*.tmpl templates/pack.yaml
+var defaultTemplates embed.FS
+
+const (
+	defaultTemplateDir = "templates"
+	packManifest       = "pack.yaml"
+)
+
+// packInfo is the manifest of a template pack, read from pack.yaml
+type packInfo struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Engine      string   `yaml:"engine,omitempty"`   // TeX engine used unless the configuration sets one
+	Parent      string   `yaml:"parent,omitempty"`   // pack whose templates are used for the sections not redefined here
+	Sections    []string `yaml:"sections,omitempty"` // sections the pack can render, all when empty
+	Packages    []string `yaml:"packages,omitempty"` // LaTeX packages the templates need
+}
+
+// templatePack is a directory of templates with its manifest. A pack inherits
+// the templates of its parent and overrides the ones it defines again.
+type templatePack struct {
+	packInfo
+	dir    string // empty for the built-in pack
+	files  fs.FS
+	parent *templatePack
+}
+
+func (p *templatePack) String() string {
+	if p.dir == "" {
+		return p.Name + " (built-in)"
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, p.dir)
+}
+
+// chain returns the packs to parse, the furthest ancestor first
+func (p *templatePack) chain() []*templatePack {
+	if p.parent == nil {
+		return []*templatePack{p}
+	}
+	return append(p.parent.chain(), p)
+}
+
+// engine returns the TeX engine of the pack or of its closest ancestor
+func (p *templatePack) engine() string {
+	for ; p != nil; p = p.parent {
+		if p.Engine != "" {
+			return p.Engine
+		}
+	}
+	return ""
+}
+
+// supports reports whether the pack or its ancestors declare the section
+func (p *templatePack) supports(section string) bool {
+	for ; p != nil; p = p.parent {
+		if len(p.Sections) > 0 {
+			return contains(p.Sections, section)
+		}
+	}
+	return true
+}
+
+// packages returns the LaTeX packages needed by the pack and its ancestors
+func (p *templatePack) packages() []string {
+	var pkgs []string
+	for _, pack := range p.chain() {
+		for _, pkg := range pack.Packages {
+			if !contains(pkgs, pkg) {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+	}
+	return pkgs
+}
+
+// checkPackages warns about the packages that kpsewhich cannot find, those of
+// the pack and the one of the theme font. It does nothing when kpsewhich is
+// not installed, e.g. with tectonic.
+func (p *templatePack) checkPackages(th theme) {
+	if _, err := exec.LookPath("kpsewhich"); err != nil {
+		return
+	}
+	check := func(pkg, by string) {
+		out, err := exec.Command("kpsewhich", pkg+".sty").Output()
+		if err != nil || strings.TrimSpace(string(out)) == "" {
+			log.Warnf("LaTeX package %s required by %s was not found", pkg, by)
+		}
+	}
+	pkgs := p.packages()
+	for _, pkg := range pkgs {
+		check(pkg, "template pack "+p.Name)
+	}
+	if pkg := th.fontPackage(); pkg != "" && !contains(pkgs, pkg) {
+		check(pkg, "theme font "+th.Font)
+	}
+}
+
+// templates lists the templates the pack defines itself, the html_ ones for
+// HTML and the others for LaTeX and Markdown
+func (p *templatePack) templates(html bool) ([]string, error) {
+	files, err := fs.Glob(p.files, "*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("Error listing templates of pack %s: %w", p.Name, err)
+	}
+	var out []string
+	for _, f := range files {
+		if strings.HasPrefix(f, "html_") == html {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// readPack reads the pack in files. A directory without a manifest is a
+// pack named after the directory.
+func readPack(dir string, files fs.FS) (*templatePack, error) {
+	p := &templatePack{dir: dir, files: files}
+	data, err := fs.ReadFile(files, packManifest)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("Error reading %s: %w", path.Join(dir, packManifest), err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &p.packInfo); err != nil {
+			return nil, fmt.Errorf("Error parsing %s: %w", path.Join(dir, packManifest), err)
+		}
+	}
+	if p.Name == "" {
+		p.Name = filepath.Base(dir)
+	}
+	if p.Engine != "" {
+		if _, err := getEngine(p.Engine); err != nil {
+			return nil, fmt.Errorf("template pack %s: %w", p.Name, err)
+		}
+	}
+	return p, nil
+}
+
+func builtinPack() *templatePack {
+	sub, err := fs.Sub(defaultTemplates, defaultTemplateDir)
+	if err != nil {
+		log.Fatalf("Error reading built-in templates: %v", err)
+	}
+	p, err := readPack("", sub)
+	if err != nil {
+		log.Fatalf("Error reading built-in templates: %v", err)
+	}
+	return p
+}
+
+// installedPacks returns the packs in the sub directories of packDir
+func installedPacks(packDir string) ([]*templatePack, error) {
+	packs := []*templatePack{builtinPack()}
+	if packDir == "" {
+		return packs, nil
+	}
+	entries, err := os.ReadDir(packDir)
+	if os.IsNotExist(err) {
+		return packs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading template packs: %w", err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(packDir, e.Name())
+		p, err := readPack(dir, os.DirFS(dir))
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, p)
+	}
+	return packs, nil
+}
+
+// loadPack returns the template pack to render with, along with its parents.
+// A template directory takes precedence over a pack name, and the built-in
+// pack is used when neither is configured.
+func (c config) loadPack() (*templatePack, error) {
+	packs, err := installedPacks(c.PackDir)
+	if err != nil {
+		return nil, err
+	}
+	var p *templatePack
+	switch {
+	case c.TemplateDir != "":
+		if p, err = readPack(c.TemplateDir, os.DirFS(c.TemplateDir)); err != nil {
+			return nil, err
+		}
+	case c.Pack != "":
+		if p = findPack(packs, c.Pack); p == nil {
+			return nil, fmt.Errorf("template pack %q not found, expected one of: %s", c.Pack, strings.Join(packNames(packs), ", "))
+		}
+	default:
+		p = packs[0]
+	}
+
+	seen := map[*templatePack]bool{p: true}
+	for child := p; child.Parent != ""; child = child.parent {
+		parent := findPack(packs, child.Parent)
+		if parent == nil {
+			return nil, fmt.Errorf("parent %q of template pack %s not found", child.Parent, child.Name)
+		}
+		if seen[parent] {
+			return nil, fmt.Errorf("template pack %s inherits from itself", p.Name)
+		}
+		seen[parent] = true
+		child.parent = parent
+	}
+	return p, nil
+}
+
+// findPack returns the pack with the given name, the installed packs taking
+// precedence over the built-in one
+func findPack(packs []*templatePack, name string) *templatePack {
+	for i := len(packs) - 1; i >= 0; i-- {
+		if strings.EqualFold(packs[i].Name, name) {
+			return packs[i]
+		}
+	}
+	return nil
+}
+
+func packNames(packs []*templatePack) []string {
+	var names []string
+	for _, p := range packs {
+		if !contains(names, p.Name) {
+			names = append(names, p.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templatesCommand lists the template packs that can be selected with -pack
+func templatesCommand(p config, args []string) error {
+	if len(args) != 1 || args[0] != "list" {
+		return fmt.Errorf("usage: templates list")
+	}
+	var c config
+	if err := readConfigFile(".config", &c); err == nil {
+		overwriteStruct(&c, &p)
+		p = c
+	}
+	packs, err := installedPacks(p.PackDir)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tENGINE\tPARENT\tLOCATION\tDESCRIPTION")
+	for _, pack := range packs {
+		location := pack.dir
+		if location == "" {
+			location = "built-in"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", pack.Name, pack.Engine, pack.Parent, location, pack.Description)
+	}
+	return w.Flush()
+}
+
+// initCommand writes the built-in templates to a directory, templates by
+// default, as a starting point for customization. Existing files are never
+// overwritten.
+func initCommand(_ config, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: init [dir]")
+	}
+	dir := defaultTemplateDir
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	builtin := builtinPack().files
+	files, err := fs.Glob(builtin, "*.tmpl")
+	if err != nil {
+		return fmt.Errorf("Error listing built-in templates: %w", err)
+	}
+	files = append(files, packManifest)
+	for _, name := range files {
+		if _, err := os.Stat(path.Join(dir, name)); err == nil {
+			return fmt.Errorf("%s already exists, remove it or choose another directory", path.Join(dir, name))
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("Error creating template directory: %w", err)
+	}
+	for _, name := range files {
+		content, err := fs.ReadFile(builtin, name)
+		if err != nil {
+			return fmt.Errorf("Error reading built-in template %s: %w", name, err)
+		}
+		if err := os.WriteFile(path.Join(dir, name), content, 0644); err != nil {
+			return fmt.Errorf("Error writing template: %w", err)
+		}
+	}
+	log.Printf("Wrote %d templates to %s, use them with -templates %s", len(files)-1, dir, dir)
+	return nil
+}
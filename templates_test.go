@@ -0,0 +1,191 @@
+package main
+
+import (
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+)
+
+func TestParseTemplatesByPrefix(t *testing.T) {
+	child := &templatePack{
+		packInfo: packInfo{Name: "child", Parent: "default"},
+		files: fstest.MapFS{
+			"experience.tmpl":      {Data: []byte(`{{define "Experience"}}child{{end}}`)},
+			"html_experience.tmpl": {Data: []byte(`{{define "html_Experience"}}<p>child</p>{{end}}`)},
+		},
+		parent: builtinPack(),
+	}
+	set, err := parseTemplates(child, sectionFuncs{}, "resume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := set.(*template.Template)
+	if text.Lookup("html_Experience") != nil || text.Lookup("html_header") != nil {
+		t.Error("HTML templates were parsed as LaTeX")
+	}
+	if tmpl := text.Lookup("Experience"); tmpl == nil || tmpl.Tree.Root.String() != "child" {
+		t.Error("the child pack did not replace the Experience template")
+	}
+	if text.Lookup("header") == nil || text.Lookup("cover") == nil {
+		t.Error("the templates of the parent pack are missing")
+	}
+
+	set, err = parseTemplates(child, sectionFuncs{}, "html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := set.(*htmltemplate.Template)
+	if html.Lookup("Experience") != nil || html.Lookup("header") != nil {
+		t.Error("LaTeX templates were parsed as HTML")
+	}
+	if html.Lookup("html_Experience") == nil || html.Lookup("html_header") == nil {
+		t.Error("HTML templates are missing")
+	}
+}
+
+func TestInitCommand(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mine")
+	if err := initCommand(config{}, []string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	want, err := fs.Glob(builtinPack().files, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range want {
+		embedded, _ := fs.ReadFile(builtinPack().files, name)
+		written, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(written) != string(embedded) {
+			t.Errorf("%s was not copied: %v", name, err)
+		}
+	}
+	// The copy is a pack that renders without the built-in one
+	c := config{TemplateDir: dir}
+	p, err := c.loadPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.dir != dir || p.parent != nil {
+		t.Errorf("loaded %s with parent %v", p, p.parent)
+	}
+	if _, err := parseTemplates(p, sectionFuncs{}, "resume"); err != nil {
+		t.Error(err)
+	}
+
+	err = initCommand(config{}, []string{dir})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("a second init gave %v", err)
+	}
+	if p, err := (config{}).loadPack(); err != nil || p.dir != "" {
+		t.Errorf("without a template directory loadPack gave %v, %v", p, err)
+	}
+}
+
+func TestLoadPack(t *testing.T) {
+	packDir := t.TempDir()
+	packs := map[string]string{
+		"compact": "name: compact\nparent: default\nsections: [Experience, Skills]\npackages: [enumitem, xcolor]\n",
+		"tiny":    "name: tiny\nparent: compact\nengine: xelatex\n",
+		"loop-a":  "name: loop-a\nparent: loop-b\n",
+		"loop-b":  "name: loop-b\nparent: loop-a\n",
+		"orphan":  "name: orphan\nparent: missing\n",
+		"plain":   "",
+	}
+	for dir, manifest := range packs {
+		if err := os.MkdirAll(filepath.Join(packDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if manifest != "" {
+			if err := os.WriteFile(filepath.Join(packDir, dir, packManifest), []byte(manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	tests := []struct {
+		pack    string
+		chain   string
+		engine  string
+		wantErr string
+	}{
+		{"", "default", "pdflatex", ""},
+		{"Compact", "default,compact", "pdflatex", ""},
+		{"tiny", "default,compact,tiny", "xelatex", ""},
+		{"plain", "plain", "", ""},
+		{"loop-a", "", "", "inherits from itself"},
+		{"orphan", "", "", `parent "missing" of template pack orphan not found`},
+		{"fancy", "", "", "expected one of: compact, default, loop-a, loop-b, orphan, plain, tiny"},
+	}
+	for _, tt := range tests {
+		p, err := config{PackDir: packDir, Pack: tt.pack}.loadPack()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want %q", tt.pack, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.pack, err)
+			continue
+		}
+		var names []string
+		for _, pack := range p.chain() {
+			names = append(names, pack.Name)
+		}
+		if got := strings.Join(names, ","); got != tt.chain {
+			t.Errorf("%s: chain = %s, want %s", tt.pack, got, tt.chain)
+		}
+		if got := p.engine(); got != tt.engine {
+			t.Errorf("%s: engine = %q, want %q", tt.pack, got, tt.engine)
+		}
+	}
+
+	p, err := config{PackDir: packDir, Pack: "tiny"}.loadPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.supports("Skills") || p.supports("Education") {
+		t.Error("tiny does not inherit the sections of compact")
+	}
+	if got := strings.Join(p.packages(), ","); !strings.HasPrefix(got, "xcolor,hyperref,") || !strings.HasSuffix(got, ",ragged2e,enumitem") {
+		t.Errorf("packages = %s", got)
+	}
+}
+
+func TestPackOverride(t *testing.T) {
+	child := &templatePack{
+		packInfo: packInfo{Name: "child", Parent: "default"},
+		files: fstest.MapFS{
+			"latex_skills.tmpl": {Data: []byte(`{{define "Skills"}}\section{Only skills}{{end}}`)},
+		},
+		parent: builtinPack(),
+	}
+	r := resume{
+		Info:        info{Name: "Jane Doe"},
+		Skills:      []skill{{Name: "Languages", Keywords: []string{"Go"}}},
+		Experiences: []experience{{Company: "Acme", Title: "Engineer"}},
+	}
+	order, err := sectionRegistry(defaultSections).parseOrder("xs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := r.execTmpl(child, dir, "test", order, "resume", false); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "test.tex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tex := string(out)
+	if !strings.Contains(tex, `\section{Only skills}`) || strings.Contains(tex, "Languages") {
+		t.Errorf("the child pack did not replace the Skills template:\n%s", tex)
+	}
+	if !strings.Contains(tex, "Acme") || !strings.Contains(tex, `\documentclass`) {
+		t.Errorf("the templates of the parent pack were not used:\n%s", tex)
+	}
+}
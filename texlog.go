@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sectionMarker is written by execTmpl before every template so that a line
+// of the generated TeX file can be traced back to the section it came from
+const sectionMarker = "% section: "
+
+// maxTexWarnings caps the warnings reported after a successful build
+const maxTexWarnings = 10
+
+// texDiag is an error or warning found in a LaTeX log
+type texDiag struct {
+	warning bool
+	line    int    // line of the .tex file, 0 when unknown
+	msg     string // e.g. "Undefined control sequence \foo"
+	section string // template section the line belongs to
+	field   string // resume field the line was rendered from, e.g. experience[2].description[4]
+}
+
+func (d texDiag) String() string {
+	var where []string
+	if d.field != "" {
+		where = append(where, d.field)
+	}
+	if d.section != "" {
+		where = append(where, d.section)
+	}
+	if d.line > 0 {
+		where = append(where, fmt.Sprintf("line %d", d.line))
+	}
+	if len(where) == 0 {
+		return d.msg
+	}
+	return fmt.Sprintf("%s (%s)", d.msg, strings.Join(where, ", "))
+}
+
+var (
+	fileLineErrRe = regexp.MustCompile(`^(?:error: )?\S*\.tex:(\d+): (.+)$`)
+	bangErrRe     = regexp.MustCompile(`^! (.+)$`)
+	contextRe     = regexp.MustCompile(`^l\.(\d+) (.*)$`)
+	boxRe         = regexp.MustCompile(`^((?:Overfull|Underfull) \\[hv]box) \(([^)]*)\)(?: in (?:paragraph|alignment) at lines (\d+)--\d+| detected at line (\d+))?`)
+	csRe          = regexp.MustCompile(`\\[A-Za-z@]+\s*$`)
+)
+
+// parseTexLog extracts the errors, undefined control sequences and
+// overfull/underfull box warnings from the log of a TeX engine
+func parseTexLog(texLog string) []texDiag {
+	var diags []texDiag
+	pending := -1 // error waiting for its l.N context line
+	scanner := bufio.NewScanner(strings.NewReader(texLog))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if m := fileLineErrRe.FindStringSubmatch(line); m != nil {
+			if isTexNoise(m[2]) {
+				continue
+			}
+			n, _ := strconv.Atoi(m[1])
+			diags = append(diags, texDiag{line: n, msg: strings.TrimSuffix(m[2], ".")})
+			pending = len(diags) - 1
+			continue
+		}
+		if m := bangErrRe.FindStringSubmatch(line); m != nil {
+			if isTexNoise(m[1]) {
+				continue
+			}
+			diags = append(diags, texDiag{msg: strings.TrimSuffix(m[1], ".")})
+			pending = len(diags) - 1
+			continue
+		}
+		if m := contextRe.FindStringSubmatch(line); m != nil && pending >= 0 {
+			d := &diags[pending]
+			if d.line == 0 {
+				d.line, _ = strconv.Atoi(m[1])
+			}
+			if strings.HasPrefix(d.msg, "Undefined control sequence") {
+				if cs := csRe.FindString(m[2]); cs != "" {
+					d.msg += " " + strings.TrimSpace(cs)
+				}
+			}
+			pending = -1
+			continue
+		}
+		if m := boxRe.FindStringSubmatch(line); m != nil {
+			d := texDiag{warning: true, msg: fmt.Sprintf("%s (%s)", m[1], m[2])}
+			if m[3] != "" {
+				d.line, _ = strconv.Atoi(m[3])
+			} else if m[4] != "" {
+				d.line, _ = strconv.Atoi(m[4])
+			}
+			diags = append(diags, d)
+		}
+	}
+	return dedupDiags(diags)
+}
+
+// isTexNoise reports the messages that only repeat that an error occurred
+func isTexNoise(msg string) bool {
+	msg = strings.TrimSpace(msg)
+	return strings.HasPrefix(msg, "==>") || strings.HasPrefix(msg, "Emergency stop")
+}
+
+// dedupDiags drops the diagnostics reported twice, e.g. by latexmk passes
+func dedupDiags(diags []texDiag) []texDiag {
+	seen := map[texDiag]bool{}
+	out := diags[:0]
+	for _, d := range diags {
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		out = append(out, d)
+	}
+	return out
+}
+
+// fieldValue is a string of the resume with its path, e.g. skills[0].name
+type fieldValue struct {
+	path  string
+	value string
+}
+
+// fieldValues lists the non-empty strings of the resume, longest first
+func (r *resume) fieldValues() []fieldValue {
+	var out []fieldValue
+	collectFields(reflect.ValueOf(r).Elem(), "", &out)
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i].value) > len(out[j].value) })
+	return out
+}
+
+func collectFields(v reflect.Value, path string, out *[]fieldValue) {
+	switch v.Kind() {
+	case reflect.String:
+		if s := strings.TrimSpace(v.String()); len(s) > 2 {
+			*out = append(*out, fieldValue{path: path, value: s})
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			// Fields that are not escaped, like the theme, are not text of the page
+			if f.PkgPath != "" || f.Tag.Get("sanitize") == "-" {
+				continue
+			}
+			name := yamlName(f)
+			if name == "-" {
+				continue
+			}
+			// A bullet is written as a plain string in the resume
+			if t == reflect.TypeOf(bullet{}) && name == "text" {
+				collectFields(v.Field(i), path, out)
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			collectFields(v.Field(i), name, out)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectFields(v.Index(i), fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	}
+}
+
+// locate fills in the section and resume field of every diagnostic from the
+// generated TeX source. The resume must be escaped for LaTeX, as the TeX
+// file was rendered from, so that the strings match. Fields are named by
+// their path in the resume file, before tag filtering.
+func (r *resume) locate(diags []texDiag, tex string) {
+	lines := strings.Split(tex, "\n")
+	fields := r.fieldValues()
+	for i := range diags {
+		d := &diags[i]
+		if d.line < 1 || d.line > len(lines) {
+			continue
+		}
+		for l := d.line - 1; l >= 0; l-- {
+			if s, ok := strings.CutPrefix(strings.TrimSpace(lines[l]), sectionMarker); ok {
+				d.section = s
+				break
+			}
+		}
+		for _, f := range fields {
+			if strings.Contains(lines[d.line-1], f.value) {
+				d.field = r.sourcePath(f.path)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const testTexLog = `This is pdfTeX, Version 3.141592653
+(./resume.tex
+! Undefined control sequence.
+l.12 Built the API \foo
+
+Overfull \hbox (12.5pt too wide) in paragraph at lines 20--21
+Underfull \vbox (badness 10000) detected at line 30
+./resume.tex:40: LaTeX Error: Environment itemz undefined.
+l.40 \begin{itemz}
+./resume.tex:40: LaTeX Error: Environment itemz undefined.
+l.40 \begin{itemz}
+! Emergency stop.
+!  ==> Fatal error occurred, no output PDF file produced!
+Overfull \hbox (12.5pt too wide) in paragraph at lines 20--21
+)`
+
+func TestParseTexLog(t *testing.T) {
+	want := []texDiag{
+		{line: 12, msg: `Undefined control sequence \foo`},
+		{warning: true, line: 20, msg: `Overfull \hbox (12.5pt too wide)`},
+		{warning: true, line: 30, msg: `Underfull \vbox (badness 10000)`},
+		{line: 40, msg: "LaTeX Error: Environment itemz undefined"},
+	}
+	if got := parseTexLog(testTexLog); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTexLog =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := parseTexLog("Output written on resume.pdf (1 page).\n"); len(got) != 0 {
+		t.Errorf("a clean log gave %+v", got)
+	}
+}
+
+func TestTexDiagString(t *testing.T) {
+	tests := []struct {
+		d    texDiag
+		want string
+	}{
+		{texDiag{msg: "Emergency"}, "Emergency"},
+		{texDiag{line: 3, msg: "Oops"}, "Oops (line 3)"},
+		{texDiag{line: 3, msg: "Oops", section: "experience", field: "experience[0].title"}, "Oops (experience[0].title, experience, line 3)"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLocate(t *testing.T) {
+	r := resume{Experiences: []experience{{
+		Company: "Acme",
+		Title:   "Engineer",
+		Description: []bullet{
+			{Text: "Built the API"},
+			{Text: "Built the API gateway"},
+		},
+	}}}
+	tex := "\\documentclass{article}\n" +
+		sectionMarker + "experience\n" +
+		"\\textbf{Acme} Engineer\n" +
+		"\\item Built the API gateway \\foo\n" +
+		"\\item Built the API\n"
+	diags := []texDiag{{line: 4}, {line: 5}, {line: 1}, {line: 99}}
+	r.locate(diags, tex)
+	want := []texDiag{
+		{line: 4, section: "experience", field: "experience[0].description[1]"},
+		{line: 5, section: "experience", field: "experience[0].description[0]"},
+		{line: 1},
+		{line: 99},
+	}
+	if !reflect.DeepEqual(diags, want) {
+		t.Errorf("locate =\n%+v\nwant\n%+v", diags, want)
+	}
+}
+
+func TestLocateTheme(t *testing.T) {
+	r := resume{
+		Info:  info{Name: "Jane Doe"},
+		Theme: theme{BulletSpacing: "-7pt", FontSize: "11pt"},
+	}
+	tex := "\\documentclass[11pt]{article}\n\\item Built it \\vspace{-7pt}\n"
+	diags := []texDiag{{line: 1}, {line: 2}}
+	r.locate(diags, tex)
+	for _, d := range diags {
+		if d.field != "" {
+			t.Errorf("line %d was traced to %s", d.line, d.field)
+		}
+	}
+}
+
+func TestLocateFiltered(t *testing.T) {
+	var r resume
+	err := yaml.Unmarshal([]byte(`experience:
+  - company: Acme
+    title: Engineer
+    tags: [backend]
+  - company: Globex
+    tags: [design]
+    roles:
+      - title: Backend
+        tags: [backend]
+  - company: Studio
+    title: Designer
+    tags: [design]
+    description:
+      - text: Wrote the style guide
+        tags: [backend]
+      - text: Drew the logo
+        tags: [design]
+`), &r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.filterTags(config{Tags: "design", ExcludeUntagged: true})
+	if len(r.Experiences) != 1 || len(r.Experiences[0].Description) != 1 {
+		t.Fatalf("filtered into %+v", r.Experiences)
+	}
+	tex := sectionMarker + "Experience\n\\textbf{Studio} Designer\n\\item Drew the logo \\foo\n"
+	diags := []texDiag{{line: 2}, {line: 3}}
+	r.locate(diags, tex)
+	want := []string{"experience[2].title", "experience[2].description[1]"}
+	for i, d := range diags {
+		if d.field != want[i] {
+			t.Errorf("line %d was traced to %q, want %q", d.line, d.field, want[i])
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// theme holds the visual settings read by the templates as .Theme. It can be
+// set in the configuration and in the resume, the resume taking precedence.
+// Lengths are TeX lengths that are also valid in CSS: pt, in, cm, mm, em or ex.
+type theme struct {
+	Font           string  `yaml:"font,omitempty" schema:"pattern=^[A-Za-z0-9][A-Za-z0-9 ._-]*$"`                      // Font family: lato, helvetica, charter, palatino, times, roboto, sourcesans or computer-modern, or the name of a system font with xelatex, lualatex or tectonic (Optional) Example: lato
+	FontSize       string  `yaml:"font_size,omitempty" schema:"enum=10pt|11pt|12pt"`                                   // Base font size (Optional) Example: 11pt
+	Margins        margins `yaml:"margins,omitempty"`                                                                  // Page margins of the resume (Optional)
+	AccentColor    string  `yaml:"accent_color,omitempty" schema:"pattern=^#?[0-9A-Fa-f]{6}$"`                         // Hex color of the name and section titles (Optional) Example: #1F4E79
+	LinkColor      string  `yaml:"link_color,omitempty" schema:"pattern=^#?[0-9A-Fa-f]{6}$"`                           // Hex color of the links, the text color when empty (Optional) Example: #0645AD
+	SectionSpacing string  `yaml:"section_spacing,omitempty" schema:"pattern=^-?[0-9]*\\.?[0-9]+(pt|in|cm|mm|em|ex)$"` // Space before a section title, negative values tighten the page (Optional) Example: -10pt
+	BulletSpacing  string  `yaml:"bullet_spacing,omitempty" schema:"pattern=^-?[0-9]*\\.?[0-9]+(pt|in|cm|mm|em|ex)$"`  // Space after each bullet, negative values tighten the page (Optional) Example: -7pt
+	DateFormat     string  `yaml:"date_format,omitempty"`                                                              // Go layout of the dates, written as January 2 2006 (Optional) Example: 01/2006
+	Locale         string  `yaml:"locale,omitempty" schema:"enum=en|de|fr|es|it|pt|nl|pl"`                             // Language of the month and season names (Optional) Example: de
+	unicode        bool    // the TeX engine loads fonts with fontspec
+}
+
+type margins struct {
+	Top    string `yaml:"top,omitempty" schema:"pattern=^[0-9]*\\.?[0-9]+(pt|in|cm|mm|em|ex)$"`    // Top margin (Optional) Example: 0.5in
+	Bottom string `yaml:"bottom,omitempty" schema:"pattern=^[0-9]*\\.?[0-9]+(pt|in|cm|mm|em|ex)$"` // Bottom margin (Optional) Example: 0.5in
+	Left   string `yaml:"left,omitempty" schema:"pattern=^[0-9]*\\.?[0-9]+(pt|in|cm|mm|em|ex)$"`   // Left margin (Optional) Example: 0.5in
+	Right  string `yaml:"right,omitempty" schema:"pattern=^[0-9]*\\.?[0-9]+(pt|in|cm|mm|em|ex)$"`  // Right margin (Optional) Example: 0.5in
+}
+
+// defaultTheme reproduces the look of the templates before themes existed
+var defaultTheme = theme{
+	Font:           "lato",
+	FontSize:       "10pt",
+	Margins:        margins{Top: "0.15in", Bottom: "0.10in", Left: "0.5in", Right: "0.5in"},
+	AccentColor:    "000000",
+	SectionSpacing: "-10pt",
+	BulletSpacing:  "-7pt",
+	DateFormat:     "Jan 2006",
+	Locale:         "en",
+}
+
+// themeFont is how a font is loaded in LaTeX and named in CSS
+type themeFont struct {
+	latex  string
+	pkg    string // LaTeX package loaded by latex, checked like the packages of the pack
+	system string // OpenType font set with fontspec by the Unicode engines, Latin Modern when empty
+	css    string
+}
+
+var themeFonts = map[string]themeFont{
+	"lato":            {`\usepackage[default]{lato}`, "lato", "Lato", `"Lato", "Helvetica Neue", Arial, sans-serif`},
+	"helvetica":       {`\usepackage[scaled]{helvet}\renewcommand{\familydefault}{\sfdefault}`, "helvet", "TeX Gyre Heros", `"Helvetica Neue", Helvetica, Arial, sans-serif`},
+	"charter":         {`\usepackage{charter}`, "charter", "XCharter", `Charter, "Bitstream Charter", Georgia, serif`},
+	"palatino":        {`\usepackage{mathpazo}`, "mathpazo", "TeX Gyre Pagella", `"Palatino Linotype", Palatino, serif`},
+	"times":           {`\usepackage{mathptmx}`, "mathptmx", "TeX Gyre Termes", `"Times New Roman", Times, serif`},
+	"roboto":          {`\usepackage[sfdefault]{roboto}`, "roboto", "Roboto", `Roboto, Arial, sans-serif`},
+	"sourcesans":      {`\usepackage[default]{sourcesanspro}`, "sourcesanspro", "Source Sans Pro", `"Source Sans Pro", Arial, sans-serif`},
+	"computer-modern": {``, "", "", `"Latin Modern Roman", "Computer Modern", serif`},
+}
+
+// builtinFonts lists the names of the fonts that every engine can load
+func builtinFonts() string {
+	names := make([]string, 0, len(themeFonts))
+	for name := range themeFonts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// applyTheme sets the theme of the resume to the default theme overridden by
+// the configuration and then by the resume, and validates it
+func (r *resume) applyTheme(c config) error {
+	t := defaultTheme
+	for _, layer := range []theme{c.Theme, r.Theme} {
+		if err := overwriteStruct(&t, &layer); err != nil {
+			return err
+		}
+	}
+	if err := checkSchemaTags(reflect.ValueOf(t), "theme"); err != nil {
+		return err
+	}
+	engine, err := getEngine(c.Engine)
+	if err != nil {
+		return err
+	}
+	t.unicode = engine.unicode
+	if _, ok := themeFonts[t.Font]; !ok && !t.unicode {
+		return fmt.Errorf("theme.font: %q is not one of %s, system fonts need xelatex, lualatex or tectonic", t.Font, builtinFonts())
+	}
+	r.Theme = t
+	return nil
+}
+
+// checkSchemaTags checks the strings of v against the enum and pattern
+// options of their schema tags, so that a value is valid even when the
+// schema validation is skipped
+func checkSchemaTags(v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := path + "." + strings.Split(f.Tag.Get("yaml"), ",")[0]
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			if err := checkSchemaTags(fv, name); err != nil {
+				return err
+			}
+			continue
+		}
+		if fv.Kind() != reflect.String || fv.String() == "" {
+			continue
+		}
+		for _, opt := range strings.Split(f.Tag.Get("schema"), ",") {
+			k, val, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch k {
+			case "enum":
+				if !contains(strings.Split(val, "|"), fv.String()) {
+					return fmt.Errorf("%s: %q must be one of %s", name, fv.String(), strings.ReplaceAll(strings.Trim(val, "|"), "|", ", "))
+				}
+			case "pattern":
+				if !regexp.MustCompile(val).MatchString(fv.String()) {
+					return fmt.Errorf("%s: %q does not match %s", name, fv.String(), val)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// FontPackage returns the LaTeX that loads the font: its package with
+// pdflatex, fontspec with the engines that read system fonts
+func (t theme) FontPackage() string {
+	f, ok := themeFonts[t.Font]
+	if !t.unicode {
+		return f.latex
+	}
+	name := t.Font
+	if ok {
+		name = f.system
+	}
+	if name == "" {
+		return `\usepackage{fontspec}`
+	}
+	return `\usepackage{fontspec}\setmainfont{` + name + `}`
+}
+
+// fontPackage returns the LaTeX package that FontPackage loads, if any
+func (t theme) fontPackage() string {
+	if t.unicode {
+		return "fontspec"
+	}
+	return themeFonts[t.Font].pkg
+}
+
+// CSSFont returns the CSS font-family of the font
+func (t theme) CSSFont() template.CSS {
+	if f, ok := themeFonts[t.Font]; ok {
+		return template.CSS(f.css)
+	}
+	// The schema pattern keeps quotes and semicolons out of system font names
+	return template.CSS(`"` + t.Font + `", sans-serif`)
+}
+
+// Accent returns the accent color as six hex digits, without #
+func (t theme) Accent() string {
+	return strings.ToUpper(strings.TrimPrefix(t.AccentColor, "#"))
+}
+
+// Link returns the link color as six hex digits, without #
+func (t theme) Link() string {
+	return strings.ToUpper(strings.TrimPrefix(t.LinkColor, "#"))
+}
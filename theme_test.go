@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThemeFont(t *testing.T) {
+	tests := []struct {
+		engine, font  string
+		latex, css    string
+		pkg, errorMsg string
+	}{
+		{"", "charter", `\usepackage{charter}`, `Charter, "Bitstream Charter", Georgia, serif`, "charter", ""},
+		{"xelatex", "charter", `\usepackage{fontspec}\setmainfont{XCharter}`, `Charter, "Bitstream Charter", Georgia, serif`, "fontspec", ""},
+		{"lualatex", "computer-modern", `\usepackage{fontspec}`, `"Latin Modern Roman", "Computer Modern", serif`, "fontspec", ""},
+		{"tectonic", "Inter", `\usepackage{fontspec}\setmainfont{Inter}`, `"Inter", sans-serif`, "fontspec", ""},
+		{"pdflatex", "Inter", "", "", "", "system fonts need xelatex"},
+		{"xelatex", `Inter}\input{x`, "", "", "", "does not match"},
+	}
+	for _, tt := range tests {
+		var r resume
+		r.Theme.Font = tt.font
+		err := r.applyTheme(config{Engine: tt.engine})
+		if tt.errorMsg != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Errorf("%s %q: error = %v, want %q", tt.engine, tt.font, err, tt.errorMsg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.engine, tt.font, err)
+			continue
+		}
+		if got := r.Theme.FontPackage(); got != tt.latex {
+			t.Errorf("%s %q: FontPackage = %q, want %q", tt.engine, tt.font, got, tt.latex)
+		}
+		if got := string(r.Theme.CSSFont()); got != tt.css {
+			t.Errorf("%s %q: CSSFont = %q, want %q", tt.engine, tt.font, got, tt.css)
+		}
+		if got := r.Theme.fontPackage(); got != tt.pkg {
+			t.Errorf("%s %q: fontPackage = %q, want %q", tt.engine, tt.font, got, tt.pkg)
+		}
+	}
+}
+
+func TestApplyTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  theme
+		resume  theme
+		want    theme
+		wantErr string
+	}{
+		{"default", theme{}, theme{}, defaultTheme, ""},
+		{
+			"config", theme{Font: "charter", Margins: margins{Left: "1in"}}, theme{},
+			theme{Font: "charter", FontSize: "10pt", Margins: margins{Top: "0.15in", Bottom: "0.10in", Left: "1in", Right: "0.5in"}, AccentColor: "000000", SectionSpacing: "-10pt", BulletSpacing: "-7pt", DateFormat: "Jan 2006", Locale: "en"}, "",
+		},
+		{
+			"resume over config", theme{Font: "charter", AccentColor: "#1F4E79", Margins: margins{Left: "1in"}}, theme{Font: "times", Margins: margins{Top: "1cm"}},
+			theme{Font: "times", FontSize: "10pt", Margins: margins{Top: "1cm", Bottom: "0.10in", Left: "1in", Right: "0.5in"}, AccentColor: "#1F4E79", SectionSpacing: "-10pt", BulletSpacing: "-7pt", DateFormat: "Jan 2006", Locale: "en"}, "",
+		},
+		{"invalid config", theme{FontSize: "9pt"}, theme{}, theme{}, `theme.font_size: "9pt" must be one of 10pt, 11pt, 12pt`},
+		{"invalid resume", theme{}, theme{Margins: margins{Right: "1px"}}, theme{}, `theme.margins.right: "1px" does not match`},
+	}
+	for _, tt := range tests {
+		r := resume{Theme: tt.resume}
+		err := r.applyTheme(config{Theme: tt.config})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if r.Theme != tt.want {
+			t.Errorf("%s: theme =\n%+v\nwant\n%+v", tt.name, r.Theme, tt.want)
+		}
+	}
+}
+
+func TestThemeTemplates(t *testing.T) {
+	r := resume{
+		Info:        info{Name: "Jane Doe"},
+		Experiences: []experience{{Company: "Acme", Title: "Engineer", Description: []bullet{{Text: "Built it"}}}},
+		Theme:       theme{FontSize: "12pt", AccentColor: "#1f4e79", BulletSpacing: "-3pt", Margins: margins{Left: "1in"}},
+	}
+	if err := r.applyTheme(config{}); err != nil {
+		t.Fatal(err)
+	}
+	order, err := sectionRegistry(defaultSections).parseOrder("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tmplType string
+		want     []string
+	}{
+		{"resume", []string{`\documentclass[12pt]{article}`, `\definecolor{accent}{HTML}{1F4E79}`, `left=1in`, `\usepackage[default]{lato}`, `\vspace{-3pt}`}},
+		{"html", []string{"color: #1F4E79", `font-family: "Lato"`}},
+	}
+	for _, tt := range tests {
+		out := renderTest(t, &r, order, tt.tmplType)
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output lacks %q", tt.tmplType, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// defaultGapMonths is the shortest gap reported by lint when gap_months is not set
+const defaultGapMonths = 3
+
+// overlapSlack is how much two jobs may overlap before lint reports it, so
+// that a handover within the same month is not an overlap
+const overlapSlack = 31 * 24 * time.Hour
+
+// period is an entry of the timeline, from its start to right after its end
+type period struct {
+	section    string
+	name       string
+	start, end date
+	from, to   time.Time
+	tags       []string
+}
+
+// end returns the instant right after the time the date covers, so that an
+// entry ending in 2020-12 lasts until the end of December
+func (t date) end() time.Time {
+	switch t.precision {
+	case precisionMonth:
+		return t.time.AddDate(0, 1, 0)
+	case precisionYear:
+		return t.time.AddDate(1, 0, 0)
+	case precisionWeek:
+		return t.time.AddDate(0, 0, 7)
+	case precisionSeason:
+		return t.time.AddDate(0, 3, 0)
+	}
+	return t.time.AddDate(0, 0, 1)
+}
+
+// span returns the time between the start and the end of an entry, until now
+// when it is ongoing or ends in the future. It fails when either date is not
+// a date.
+func span(start, end date, now time.Time) (from, to time.Time, err error) {
+	switch {
+	case start.IsZero():
+		return from, to, fmt.Errorf("has no start date")
+	case start.time.IsZero():
+		return from, to, fmt.Errorf("start date %q is not a date", start.text)
+	case end.ongoing:
+		to = now
+	case end.IsZero():
+		return from, to, fmt.Errorf("has no end date, use Present for an ongoing entry")
+	case end.time.IsZero():
+		return from, to, fmt.Errorf("end date %q is not a date", end.text)
+	default:
+		to = end.end()
+	}
+	from = start.time
+	if to.After(now) {
+		to = now
+	}
+	if end.time.Before(start.time) && !end.ongoing {
+		return from, to, fmt.Errorf("ends on %s before it starts on %s", end, start)
+	}
+	return from, to, nil
+}
+
+// timeline returns the periods of the experiences, one per role, and of the
+// education, with the entries whose dates could not be read
+func (r resume) timeline(now time.Time) ([]period, []string) {
+	var periods []period
+	var issues []string
+	add := func(section, name string, start, end date, tags []string) {
+		from, to, err := span(start, end, now)
+		switch {
+		case err != nil:
+			issues = append(issues, fmt.Sprintf("%s %s: %v", section, name, err))
+		case from.After(now):
+			// Not started yet, nothing to count
+		default:
+			periods = append(periods, period{section: section, name: name, start: start, end: end, from: from, to: to, tags: tags})
+		}
+	}
+	for _, e := range r.Experiences {
+		if len(e.Roles) == 0 {
+			add("experience", e.Company+", "+e.Title, e.StartDate, e.EndDate, e.Tags)
+			continue
+		}
+		for _, role := range e.Roles {
+			add("experience", e.Company+", "+role.Title, role.StartDate, role.EndDate, append(append([]string{}, e.Tags...), role.Tags...))
+		}
+	}
+	for _, s := range r.Education {
+		add("education", s.Name, s.StartDate, s.EndDate, s.Tags)
+	}
+	return periods, issues
+}
+
+// mergePeriods returns the periods joined where they overlap, sorted by start
+func mergePeriods(periods []period) []period {
+	sorted := append([]period{}, periods...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].from.Before(sorted[j].from) })
+	var merged []period
+	for _, p := range sorted {
+		if n := len(merged); n > 0 && !p.from.After(merged[n-1].to) {
+			if p.to.After(merged[n-1].to) {
+				merged[n-1].to = p.to
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// months returns the whole months of a duration, counting a month that is
+// more than half over
+func months(d time.Duration) int {
+	return int(d.Hours()/24/30.44 + 0.5)
+}
+
+// formatMonths shows a number of months as years and months, e.g. 2 yrs 3 mos
+func formatMonths(n int) string {
+	if n <= 0 {
+		return "less than a month"
+	}
+	var parts []string
+	switch y := n / 12; {
+	case y == 1:
+		parts = append(parts, "1 yr")
+	case y > 1:
+		parts = append(parts, fmt.Sprintf("%d yrs", y))
+	}
+	switch m := n % 12; {
+	case m == 1:
+		parts = append(parts, "1 mo")
+	case m > 1:
+		parts = append(parts, fmt.Sprintf("%d mos", m))
+	}
+	return strings.Join(parts, " ")
+}
+
+// duration returns how long an entry lasted until now, empty when its dates
+// are not dates
+func duration(start, end date, now time.Time) string {
+	from, to, err := span(start, end, now)
+	if err != nil || from.After(to) {
+		return ""
+	}
+	return formatMonths(months(to.Sub(from)))
+}
+
+// totalYears returns the whole years of experience, overlapping jobs counted
+// once, until now. With tags only the experiences and roles with one of
+// them count.
+func (r resume) totalYears(now time.Time, tags ...string) int {
+	periods, _ := r.timeline(now)
+	var work []period
+	for _, p := range periods {
+		if p.section != "experience" {
+			continue
+		}
+		if len(tags) > 0 && !hasAnyTag(p.tags, tags) {
+			continue
+		}
+		work = append(work, p)
+	}
+	var total time.Duration
+	for _, p := range mergePeriods(work) {
+		total += p.to.Sub(p.from)
+	}
+	return int(total.Hours() / 24 / 365.25)
+}
+
+// hasAnyTag reports whether have holds one of the wanted tags, ignoring case
+func hasAnyTag(have, want []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(h, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lintTimeline reports the entries that cannot be placed on the timeline,
+// the gaps of more than gapMonths without work or study and the jobs that
+// overlap. It returns the durations of the entries and the issues found.
+func (r resume) lintTimeline(gapMonths int, now time.Time) (report, issues []string) {
+	if gapMonths <= 0 {
+		gapMonths = defaultGapMonths
+	}
+	periods, issues := r.timeline(now)
+	var work []period
+	var total time.Duration
+	for _, p := range periods {
+		report = append(report, fmt.Sprintf("%s %s: %s - %s, %s", p.section, p.name, p.start, p.end, formatMonths(months(p.to.Sub(p.from)))))
+		if p.section == "experience" {
+			work = append(work, p)
+		}
+	}
+	for _, p := range mergePeriods(work) {
+		total += p.to.Sub(p.from)
+	}
+	report = append(report, fmt.Sprintf("total experience: %s", formatMonths(months(total))))
+
+	// A gap starts when the entry that ends last so far is over
+	sorted := append([]period{}, periods...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].from.Before(sorted[j].from) })
+	var last period
+	for i, p := range sorted {
+		if m := months(p.from.Sub(last.to)); i > 0 && m > gapMonths {
+			issues = append(issues, fmt.Sprintf("gap of %s between %s and %s", formatMonths(m), last.name, p.name))
+		}
+		if i == 0 || p.to.After(last.to) {
+			last = p
+		}
+	}
+	if m := months(now.Sub(last.to)); len(sorted) > 0 && m > gapMonths {
+		issues = append(issues, fmt.Sprintf("gap of %s since %s ended", formatMonths(m), last.name))
+	}
+
+	sort.Slice(work, func(i, j int) bool { return work[i].from.Before(work[j].from) })
+	for i, a := range work {
+		for _, b := range work[i+1:] {
+			if !b.from.Before(a.to) {
+				break
+			}
+			end := a.to
+			if b.to.Before(end) {
+				end = b.to
+			}
+			if end.Sub(b.from) > overlapSlack {
+				issues = append(issues, fmt.Sprintf("%s overlaps %s by %s", a.name, b.name, formatMonths(months(end.Sub(b.from)))))
+			}
+		}
+	}
+	return report, issues
+}
+
+// lintCommand reports the durations, gaps and overlaps of the timeline of a resume
+func lintCommand(c config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: lint <resume.yml>")
+	}
+	var r resume
+	if err := r.loadResume(c, args[0]); err != nil {
+		return err
+	}
+	report, issues := r.lintTimeline(c.GapMonths, time.Now())
+	for _, line := range report {
+		fmt.Println(line)
+	}
+	if len(issues) > 0 {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		return fmt.Errorf("%d timeline issues found", len(issues))
+	}
+	log.Infof("No timeline issues found")
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func jobAt(company, start, end string, tags ...string) experience {
+	return experience{Company: company, Title: "Engineer", StartDate: parseDate(start), EndDate: parseDate(end), Tags: tags}
+}
+
+func TestMergePeriods(t *testing.T) {
+	day := func(s string) time.Time { return parseDate(s).time }
+	p := func(from, to string) period { return period{from: day(from), to: day(to)} }
+	tests := []struct {
+		name string
+		in   []period
+		want []period
+	}{
+		{"adjacent", []period{p("2018-01-01", "2020-01-01"), p("2015-01-01", "2018-01-01")}, []period{p("2015-01-01", "2020-01-01")}},
+		{"overlapping", []period{p("2015-01-01", "2018-07-01"), p("2018-01-01", "2020-01-01")}, []period{p("2015-01-01", "2020-01-01")}},
+		{"contained", []period{p("2015-01-01", "2020-01-01"), p("2016-01-01", "2017-01-01")}, []period{p("2015-01-01", "2020-01-01")}},
+		{"apart", []period{p("2015-01-01", "2016-01-01"), p("2017-01-01", "2018-01-01")}, []period{p("2015-01-01", "2016-01-01"), p("2017-01-01", "2018-01-01")}},
+	}
+	for _, tt := range tests {
+		if got := mergePeriods(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: mergePeriods = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLintTimeline(t *testing.T) {
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		jobs   []experience
+		issues []string
+	}{
+		{"adjacent", []experience{jobAt("A", "2015-01", "2017-12"), jobAt("B", "2018-01", "Present")}, nil},
+		{"handover in the same month", []experience{jobAt("A", "2015-01", "2018-01"), jobAt("B", "2018-01", "Present")}, nil},
+		{"overlapping", []experience{jobAt("A", "2015-01", "2018-06"), jobAt("B", "2018-01", "Present")},
+			[]string{"A, Engineer overlaps B, Engineer by 6 mos"}},
+		{"gap", []experience{jobAt("A", "2015-01", "2016-12"), jobAt("B", "2017-06", "Present")},
+			[]string{"gap of 5 mos between A, Engineer and B, Engineer"}},
+		{"gap since the last job", []experience{jobAt("A", "2015-01", "2019-11")},
+			[]string{"gap of 1 yr 1 mo since A, Engineer ended"}},
+		{"gap covered by a longer job", []experience{jobAt("A", "2010-01", "Present"), jobAt("B", "2012-01", "2013-01"), jobAt("C", "2016-01", "2017-01")},
+			[]string{"A, Engineer overlaps B, Engineer by 1 yr 1 mo", "A, Engineer overlaps C, Engineer by 1 yr 1 mo"}},
+		{"years and seasons", []experience{jobAt("A", "2015", "2016"), jobAt("B", "Summer 2017", "Fall 2017"), jobAt("C", "Winter 2017", "Present")},
+			[]string{"gap of 5 mos between A, Engineer and B, Engineer"}},
+		{"unreadable dates", []experience{jobAt("A", "", "2016-01"), jobAt("B", "2016-01", "TBD"), jobAt("C", "2015-01", "Present")},
+			[]string{"experience A, Engineer: has no start date", `experience B, Engineer: end date "TBD" is not a date`}},
+		{"ends before it starts", []experience{jobAt("A", "2018-01", "2016-01"), jobAt("B", "2015-01", "Present")},
+			[]string{"experience A, Engineer: ends on Jan 2016 before it starts on Jan 2018"}},
+	}
+	for _, tt := range tests {
+		r := resume{Experiences: tt.jobs}
+		_, issues := r.lintTimeline(3, now)
+		if !reflect.DeepEqual(issues, tt.issues) {
+			t.Errorf("%s: issues = %q, want %q", tt.name, issues, tt.issues)
+		}
+	}
+}
+
+func TestLintTimelineEducation(t *testing.T) {
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	r := resume{
+		Education:   []school{{Name: "Uni", StartDate: parseDate("2011-09"), EndDate: parseDate("2015-05")}},
+		Experiences: []experience{jobAt("A", "2015-07", "Present")},
+	}
+	report, issues := r.lintTimeline(0, now)
+	if len(issues) != 0 {
+		t.Errorf("issues = %q, want none", issues)
+	}
+	want := []string{
+		"experience A, Engineer: Jul 2015 - Present, 5 yrs 6 mos",
+		"education Uni: Sep 2011 - May 2015, 3 yrs 9 mos",
+		"total experience: 5 yrs 6 mos",
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %q, want %q", report, want)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		start, end date
+		want       string
+	}{
+		{parseDate("2015-01"), parseDate("2015-12"), "1 yr"},
+		{parseDate("2015-01"), parseDate("2015-01"), "1 mo"},
+		{parseDate("2015"), parseDate("2016"), "2 yrs"},
+		{parseDate("Summer 2017"), parseDate("Fall 2017"), "6 mos"},
+		{parseDate("2020-01-01"), parseDate("2020-01-10"), "less than a month"},
+		{date{time: now.AddDate(-2, -3, 0)}, parseDate("Present"), "2 yrs 3 mos"},
+		{parseDate("2015-01"), parseDate("TBD"), ""},
+		{date{}, parseDate("2015-01"), ""},
+		{date{time: now.AddDate(1, 0, 0)}, parseDate("Present"), ""},
+	}
+	for _, tt := range tests {
+		if got := duration(tt.start, tt.end, now); got != tt.want {
+			t.Errorf("duration(%s, %s) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestTotalYears(t *testing.T) {
+	r := resume{Experiences: []experience{
+		jobAt("A", "2010-01", "2014-12"),
+		jobAt("B", "2012-01", "2016-12", "go"),
+		{Company: "C", Roles: []role{
+			{Title: "Junior", StartDate: parseDate("2005"), EndDate: parseDate("2006"), Tags: []string{"Go"}},
+			{Title: "Senior", StartDate: parseDate("2007"), EndDate: parseDate("2008")},
+		}},
+	}}
+	tests := []struct {
+		tags []string
+		want int
+	}{
+		{nil, 11},
+		{[]string{"go"}, 7},
+		{[]string{"rust"}, 0},
+	}
+	for _, tt := range tests {
+		if got := r.totalYears(time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), tt.tags...); got != tt.want {
+			t.Errorf("totalYears(%q) = %d, want %d", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestTimelineTemplateFuncs(t *testing.T) {
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	r := resume{Experiences: []experience{jobAt("A", "2015-07", "Present", "go")}}
+	pack := &templatePack{
+		packInfo: packInfo{Name: "test"},
+		files: fstest.MapFS{
+			"test.tmpl": {Data: []byte(`{{define "test"}}{{range .Experiences}}{{duration .StartDate .EndDate}}{{end}}, {{totalYears}}, {{totalYears "rust"}}, {{today}}{{end}}`)},
+		},
+	}
+	set, err := parseTemplates(pack, sectionFuncs{resume: &r, now: now}, "resume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := set.ExecuteTemplate(&out, "test", r); err != nil {
+		t.Fatal(err)
+	}
+	if want := "5 yrs 6 mos, 5, 0, 2021-01-01"; out.String() != want {
+		t.Errorf("rendered %q, want %q", out.String(), want)
+	}
+}
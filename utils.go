@@ -2,45 +2,158 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime"
 	"strings"
+	"unicode"
 
 	"github.com/charmbracelet/log"
 )
 
 func (s *resume) sanitizeResume() error {
 	v := reflect.ValueOf(s).Elem()
-	walkStruct(v)
+	return walkStruct(v)
+}
+
+// latex returns a copy of the resume escaped for LaTeX
+func (s *resume) latex() (*resume, error) {
+	escaped := deepCopy(reflect.ValueOf(*s)).Interface().(resume)
+	if err := escaped.sanitizeResume(); err != nil {
+		return nil, err
+	}
+	return &escaped, nil
+}
+
+// deepCopy copies v along with the slices, maps and pointers it holds, so
+// that the copy can be changed without touching the original
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			c.SetMapIndex(k, deepCopy(v.MapIndex(k)))
+		}
+		return c
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	}
+	return v
+}
+
+// latexEscapes maps the characters that are special to LaTeX, or that the
+// default fonts lack, to markup that prints them
+var latexEscapes = map[rune]string{
+	'\\':     `\textbackslash{}`,
+	'{':      `\{`,
+	'}':      `\}`,
+	'&':      `\&`,
+	'%':      `\%`,
+	'$':      `\$`,
+	'#':      `\#`,
+	'_':      `\_`,
+	'^':      `\textasciicircum{}`,
+	'~':      `\textasciitilde{}`,
+	'<':      `\textless{}`,
+	'>':      `\textgreater{}`,
+	'|':      `\textbar{}`,
+	'`':      `\textasciigrave{}`,
+	'\u00A0': "~", // Non-breaking space
+	'\u2013': "--",
+	'\u2014': "---",
+	'\u2212': "-",
+	'\u2018': "`",
+	'\u2019': "'",
+	'\u201C': "``",
+	'\u201D': "''",
+	'\u2026': `\ldots{}`,
+	'\u2022': `\textbullet{}`,
+	'\u00B0': `\textdegree{}`,
+}
+
+// dangerousRe matches the primitives that read or write files or run commands.
+// A control word ends at the first character that is not a letter.
+var dangerousRe = regexp.MustCompile(`\\(write|immediate|input|include|openout|openin|read|directlua|ShellEscape|catcode)(?:[^A-Za-z@]|$)`)
+
+// checkPrimitives fails when escaped output still calls a dangerous primitive.
+// The escapers turn every backslash of the input into text, so this only
+// trips when an escaper lets a control sequence through.
+func checkPrimitives(escaped string) error {
+	if m := dangerousRe.FindStringSubmatch(escaped); m != nil {
+		return fmt.Errorf("security risk: \\%s found in escaped output", m[1])
+	}
 	return nil
 }
 
+// sanitize escapes a string so that LaTeX prints it as written. Every
+// backslash is escaped, so no control sequence of the input reaches LaTeX.
+// A straight double quote becomes an opening or closing quote depending on
+// its position, and "--" and "---" are left for LaTeX to turn into dashes.
+// Control characters other than newlines and tabs are dropped.
 func sanitize(in string) (string, error) {
-	replacements := map[string]string{
-		"&":    "\\&",
-		"%":    "\\%",
-		"-":    "\\textendash{}",
-		"$":    "\\$",
-		"#":    "\\#",
-		"<":    "\\textless{}",
-		">":    "\\textgreater{}",
-		"^":    "\\^",
-		"\xA0": "~", // Non-breaking space
-		"~":    "\\textasciitilde{}",
+	var out strings.Builder
+	prev := ' '
+	for _, r := range in {
+		switch {
+		case unicode.IsControl(r) && r != '\n' && r != '\t':
+			continue
+		case r == '"' && (unicode.IsSpace(prev) || strings.ContainsRune("([{", prev)):
+			out.WriteString("``")
+		case r == '"':
+			out.WriteString("''")
+		case latexEscapes[r] != "":
+			out.WriteString(latexEscapes[r])
+		default:
+			out.WriteRune(r)
+		}
+		prev = r
 	}
-
-	if strings.Contains(in, "\\write18") {
-		return "", fmt.Errorf(`security risk: \\write18 found in input`)
+	if err := checkPrimitives(out.String()); err != nil {
+		return "", err
 	}
+	return out.String(), nil
+}
 
-	re := regexp.MustCompile(`([&%$#\-<>^\xA0~])`)
-	out := re.ReplaceAllStringFunc(in, func(match string) string {
-		return replacements[match]
-	})
+// urlEscapes keeps a URL valid inside \href and \url
+var urlEscapes = strings.NewReplacer(`\`, "%5C", "{", "%7B", "}", "%7D", " ", "%20", "%", `\%`, "#", `\#`)
 
+// sanitizeURL escapes a URL for LaTeX without touching the characters that
+// hyperref reads verbatim
+func sanitizeURL(in string) (string, error) {
+	out := urlEscapes.Replace(in)
+	if err := checkPrimitives(out); err != nil {
+		return "", err
+	}
 	return out, nil
 }
 
@@ -67,35 +180,90 @@ func openFile(file string) error {
 	return nil
 }
 
-func walkStruct(v reflect.Value) {
+func walkStruct(v reflect.Value) error {
+	return walkEscape(v, sanitize)
+}
+
+// sanitizers are selected with the sanitize struct tag: "url" escapes only
+// what breaks \href, "markdown" renders inline Markdown and "-" skips the field
+var sanitizers = map[string]func(string) (string, error){
+	"url":      sanitizeURL,
+	"markdown": markdownLaTeX,
+}
+
+// walkEscape escapes every string under v, the elements of a tagged field
+// included, with the given function
+func walkEscape(v reflect.Value, escape func(string) (string, error)) error {
 	switch v.Kind() {
 	case reflect.String:
 		s := v.String()
 		if s != "" {
-			sanitized, err := sanitize(s)
+			sanitized, err := escape(s)
 			if err != nil {
-				log.Fatalf("Error sanitizing string: %v", err)
+				return fmt.Errorf("Error sanitizing string: %w", err)
 			}
 			v.SetString(sanitized)
 		}
 	case reflect.Struct:
 		for i := 0; i < v.NumField(); i++ {
-			if v.Type().Field(i).PkgPath != "" {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			tag := f.Tag.Get("sanitize")
+			if tag == "-" {
 				continue
 			}
-			walkStruct(v.Field(i))
+			fn, ok := sanitizers[tag]
+			if !ok {
+				fn = escape
+			}
+			if err := walkEscape(v.Field(i), fn); err != nil {
+				return err
+			}
 		}
 	case reflect.Slice:
 		for i := 0; i < v.Len(); i++ {
-			walkStruct(v.Index(i))
+			if err := walkEscape(v.Index(i), escape); err != nil {
+				return err
+			}
 		}
 	case reflect.Map:
 		for _, k := range v.MapKeys() {
-			walkStruct(v.MapIndex(k))
+			if err := walkEscape(v.MapIndex(k), escape); err != nil {
+				return err
+			}
 		}
-	default:
-		return
 	}
+	return nil
+}
+
+// moveFile moves a file, copying it when a rename is not possible
+// e.g. when the temporary directory is on another device
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
 }
 
 func getFilename(path string) string {
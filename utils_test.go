@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// latexSafe reports where s holds a control sequence or a special character
+// that sanitize does not produce, -1 when there is none
+func latexSafe(s string) int {
+	var known []string
+	for _, e := range latexEscapes {
+		if strings.HasPrefix(e, `\`) {
+			known = append(known, e)
+		}
+	}
+	// Longest first, so that \textbackslash{} is not read as \t...
+	sort.Slice(known, func(i, j int) bool { return len(known[i]) > len(known[j]) })
+next:
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			for _, e := range known {
+				if strings.HasPrefix(s[i:], e) {
+					i += len(e) - 1
+					continue next
+				}
+			}
+			return i
+		case '{', '}', '&', '%', '$', '#', '_', '^':
+			return i
+		}
+		if s[i] < ' ' && s[i] != '\n' && s[i] != '\t' || s[i] == 0x7f {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`C:\input\data`, `C:\textbackslash{}input\textbackslash{}data`},
+		{`\write18{rm -rf /}`, `\textbackslash{}write18\{rm -rf /\}`},
+		{"50% & $5 #1 a_b", `50\% \& \$5 \#1 a\_b`},
+		{`"quoted" text`, "``quoted'' text"},
+		{"a\u00A0b \u2013 c", "a~b -- c"},
+		{"x^2 ~ y", `x\textasciicircum{}2 \textasciitilde{} y`},
+		{"a\x00b\x1bc\nd", "abc\nd"},
+	}
+	for _, tt := range tests {
+		got, err := sanitize(tt.in)
+		if err != nil {
+			t.Errorf("sanitize(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("sanitize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWalkEscapeError(t *testing.T) {
+	type doc struct {
+		Name string
+		Tags []string
+	}
+	d := doc{Name: "ok", Tags: []string{"fails"}}
+	fail := func(s string) (string, error) {
+		if s == "fails" {
+			return "", errors.New("cannot escape")
+		}
+		return s, nil
+	}
+	if err := walkEscape(reflect.ValueOf(&d).Elem(), fail); err == nil {
+		t.Fatal("walkEscape did not return the error of the escape function")
+	}
+}
+
+func TestCheckPrimitives(t *testing.T) {
+	for _, s := range []string{`\input{/etc/passwd}`, `\immediate\write18{ls}`, `a \openout1=x`} {
+		if err := checkPrimitives(s); err == nil {
+			t.Errorf("checkPrimitives(%q) passed", s)
+		}
+	}
+	for _, s := range []string{`C:\textbackslash{}input`, `\textbf{input}`, `\href{https://example.com/include}{x}`} {
+		if err := checkPrimitives(s); err != nil {
+			t.Errorf("checkPrimitives(%q) failed: %v", s, err)
+		}
+	}
+	// The primitives of the input are escaped, so they never trip the check
+	for _, s := range []string{`\input{/etc/passwd}`, `\write18{ls}`} {
+		if _, err := sanitize(s); err != nil {
+			t.Errorf("sanitize(%q) failed: %v", s, err)
+		}
+		if _, err := markdownLaTeX("**" + s + "**"); err != nil {
+			t.Errorf("markdownLaTeX(%q) failed: %v", s, err)
+		}
+	}
+}
+
+// compilable reports whether the fonts of pdflatex print every character of
+// s, those of ASCII and the ones sanitize replaces
+func compilable(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII && latexEscapes[r] == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// compileLaTeX compiles body with pdflatex, skipping the test when pdflatex
+// is not installed
+func compileLaTeX(t *testing.T, body string) {
+	t.Helper()
+	if _, err := exec.LookPath("pdflatex"); err != nil {
+		t.Skip("pdflatex is not installed")
+	}
+	dir := t.TempDir()
+	doc := "\\documentclass{article}\n\\usepackage{hyperref}\n\\begin{document}\n" + body + "\n\\end{document}\n"
+	if err := os.WriteFile(filepath.Join(dir, "test.tex"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("pdflatex", "-no-shell-escape", "-interaction=nonstopmode", "-halt-on-error", "test.tex")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("pdflatex failed on %q: %v\n%s", body, err, out)
+	}
+}
+
+func FuzzSanitize(f *testing.F) {
+	for _, s := range []string{
+		`C:\input\data`,
+		`\input{/etc/passwd}`,
+		`\immediate\write18{ls}`,
+		`\\\{\}`,
+		"100% of $5 & #1 a_b ^ ~",
+		"\"quoted\" \u2018text\u2019 \u2026",
+		"\u00A0\u2013\u2014\u2022\u00B0",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, in string) {
+		out, err := sanitize(in)
+		if err != nil {
+			t.Fatalf("sanitize(%q) failed: %v", in, err)
+		}
+		if i := latexSafe(out); i >= 0 {
+			t.Fatalf("sanitize(%q) = %q leaves %q unescaped at %d", in, out, out[i:], i)
+		}
+		if compilable(in) {
+			compileLaTeX(t, out)
+		}
+	})
+}